@@ -7,6 +7,7 @@ toolchain go1.24.1
 require (
 	github.com/ethereum/go-ethereum v1.16.3
 	github.com/spf13/cobra v1.10.1
+	golang.org/x/term v0.35.0
 )
 
 require (
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+type Verdict string
+
+const (
+	VerdictVerified           Verdict = "VERIFIED"
+	VerdictInvalidInput       Verdict = "INVALID_INPUT"
+	VerdictRPCFailure         Verdict = "RPC_FAILURE"
+	VerdictStateUnavailable   Verdict = "STATE_UNAVAILABLE"
+	VerdictVerificationFailed Verdict = "VERIFICATION_FAILED"
+	VerdictError              Verdict = "ERROR"
+)
+
+const (
+	ExitVerified           = 0
+	ExitError              = 1
+	ExitInvalidInput       = 2
+	ExitRPCFailure         = 3
+	ExitStateUnavailable   = 4
+	ExitVerificationFailed = 5
+)
+
+type CommandError struct {
+	Verdict Verdict
+	Err     error
+}
+
+func (e *CommandError) Error() string { return e.Err.Error() }
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+func invalidInputf(format string, args ...interface{}) error {
+	return &CommandError{Verdict: VerdictInvalidInput, Err: fmt.Errorf(format, args...)}
+}
+
+func rpcFailuref(format string, args ...interface{}) error {
+	return &CommandError{Verdict: VerdictRPCFailure, Err: fmt.Errorf(format, args...)}
+}
+
+func verificationFailedf(format string, args ...interface{}) error {
+	return &CommandError{Verdict: VerdictVerificationFailed, Err: fmt.Errorf(format, args...)}
+}
+
+func proofFetchErrorf(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	if errors.Is(err, geth.ErrStateUnavailable) {
+		return &CommandError{Verdict: VerdictStateUnavailable, Err: err}
+	}
+	return &CommandError{Verdict: VerdictRPCFailure, Err: err}
+}
+
+func inclusionProofError(result *trie.VerificationResult, item string) error {
+	if err := result.Err(); err != nil {
+		return verificationFailedf("%s proof verification failed: %v", item, err)
+	}
+	if !result.Exists() {
+		return verificationFailedf("%s is not included under the header root", item)
+	}
+	return nil
+}
+
+func verdictOf(err error) Verdict {
+	if err == nil {
+		return VerdictVerified
+	}
+	var commandErr *CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Verdict
+	}
+	return VerdictError
+}
+
+func exitCode(err error) int {
+	switch verdictOf(err) {
+	case VerdictVerified:
+		return ExitVerified
+	case VerdictInvalidInput:
+		return ExitInvalidInput
+	case VerdictRPCFailure:
+		return ExitRPCFailure
+	case VerdictStateUnavailable:
+		return ExitStateUnavailable
+	case VerdictVerificationFailed:
+		return ExitVerificationFailed
+	}
+	return ExitError
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		verdict Verdict
+		code    int
+	}{
+		{"success", nil, VerdictVerified, ExitVerified},
+		{"invalid input", invalidInputf("bad address"), VerdictInvalidInput, ExitInvalidInput},
+		{"rpc failure", rpcFailuref("dial failed"), VerdictRPCFailure, ExitRPCFailure},
+		{"proof fetch failure", proofFetchErrorf("get proof: %w", errors.New("header not found")), VerdictRPCFailure, ExitRPCFailure},
+		{"state unavailable", proofFetchErrorf("get proof: %w", geth.ErrStateUnavailable), VerdictStateUnavailable, ExitStateUnavailable},
+		{"verification failed", verificationFailedf("root mismatch"), VerdictVerificationFailed, ExitVerificationFailed},
+		{"wrapped command error", fmt.Errorf("context: %w", invalidInputf("bad slot")), VerdictInvalidInput, ExitInvalidInput},
+		{"untyped error", errors.New("boom"), VerdictError, ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verdictOf(tt.err); got != tt.verdict {
+				t.Errorf("verdict %s, want %s", got, tt.verdict)
+			}
+			if got := exitCode(tt.err); got != tt.code {
+				t.Errorf("exit code %d, want %d", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestInclusionProofError(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *trie.VerificationResult
+		verdict Verdict
+	}{
+		{"included", &trie.VerificationResult{Verified: true, Value: []byte{0x01}}, VerdictVerified},
+		{"absent", &trie.VerificationResult{Verified: true}, VerdictVerificationFailed},
+		{"failed", &trie.VerificationResult{FailedStep: 0, Reason: "proof node missing"}, VerdictVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verdictOf(inclusionProofError(tt.result, "receipt")); got != tt.verdict {
+				t.Fatalf("verdict %s, want %s", got, tt.verdict)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/trie"
+	"github.com/inchori/gethtried/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exploreTrie    string
+	exploreSlotStr string
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore",
+	Short: "Interactively explore a state proof or a block's transaction/receipt trie",
+	Run:   runCommand("explore", runExploreCommand),
+}
+
+func runExploreCommand(report *Report) error {
+	if !textOutput() {
+		return invalidInputf("explore only supports text output")
+	}
+
+	if blockHeight < 0 {
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
+	}
+
+	client, err := geth.NewEthClient(rpcURL)
+	if err != nil {
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+	}
+
+	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
+	if err != nil {
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
+	}
+
+	if uint64(blockHeight) > latestBlock.NumberU64() {
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+	}
+
+	switch exploreTrie {
+	case "state":
+		return exploreState(client)
+	case "tx", "receipt":
+		return exploreBlockTrie(client, exploreTrie)
+	}
+	return invalidInputf("invalid trie: %s (expected one of: state, tx, receipt)", exploreTrie)
+}
+
+func exploreState(client *geth.Client) error {
+	if !common.IsHexAddress(accountAddress) {
+		return invalidInputf("invalid account address format: %s (expected format: 0x...)", accountAddress)
+	}
+
+	header, err := client.GetHeaderByNumber(context.Background(), blockHeight)
+	if err != nil {
+		return rpcFailuref("failed to get block header %d: %w", blockHeight, err)
+	}
+
+	proofResult, err := client.GetAccountProof(context.Background(), accountAddress, blockHeight)
+	if err != nil {
+		return proofFetchErrorf("failed to get account proof for %s at block %d: %w", accountAddress, blockHeight, err)
+	}
+
+	proofBytes, err := decodeProof(proofResult.AccountProof)
+	if err != nil {
+		return err
+	}
+
+	address := common.HexToAddress(accountAddress)
+	accountKey := crypto.Keccak256(address.Bytes())
+	result := trie.VerifyProof(header.Root, accountKey, proofBytes)
+	if err := result.Err(); err != nil {
+		return verificationFailedf("account proof verification failed: %v", err)
+	}
+
+	frame := tui.NewProofFrame(fmt.Sprintf("State #%d", header.Number.Uint64()), result, proofBytes)
+	frame.Decode = exploreValueLines(render.ValueAccount)
+	explorer := tui.NewExplorer(frame)
+
+	var account trie.Account
+	if result.Exists() {
+		if err := rlp.DecodeBytes(result.Value, &account); err != nil {
+			return verificationFailedf("failed to decode verified account: %w", err)
+		}
+		explorer.OpenStorage = func(leafKey string, slotStr string) (*tui.Frame, error) {
+			if leafKey != hex.EncodeToString(accountKey) {
+				return nil, fmt.Errorf("storage can only be opened for %s (other account addresses are unknown)", address.Hex())
+			}
+			return exploreStorageFrame(client, address, account.Root, slotStr)
+		}
+	}
+
+	if exploreSlotStr != "" {
+		if !result.Exists() {
+			return invalidInputf("account %s has no storage: it is proven absent at block %d", address.Hex(), blockHeight)
+		}
+		storageFrame, err := exploreStorageFrame(client, address, account.Root, exploreSlotStr)
+		if err != nil {
+			return err
+		}
+		explorer.Push(storageFrame)
+	}
+
+	return explorer.Run()
+}
+
+func exploreStorageFrame(client *geth.Client, address common.Address, storageRoot common.Hash, slotStr string) (*tui.Frame, error) {
+	derivation, err := parseStorageSlot(slotStr)
+	if err != nil {
+		return nil, err
+	}
+
+	storageProof, err := client.GetStorageProof(context.Background(), address.Hex(), []common.Hash{derivation.Key}, blockHeight)
+	if err != nil {
+		return nil, proofFetchErrorf("failed to get storage proof for %s slot %s at block %d: %w", address.Hex(), derivation.Key.Hex(), blockHeight, err)
+	}
+	if len(storageProof.StorageProof) == 0 {
+		return nil, rpcFailuref("no storage proof returned for slot %s", derivation.Key.Hex())
+	}
+	if storageProof.StorageHash != storageRoot {
+		return nil, verificationFailedf("storage root mismatch: account commits to %s but RPC returned %s", storageRoot.Hex(), storageProof.StorageHash.Hex())
+	}
+
+	proofBytes, err := decodeProof(storageProof.StorageProof[0].Proof)
+	if err != nil {
+		return nil, err
+	}
+
+	result := trie.VerifyProof(storageRoot, crypto.Keccak256(derivation.Key.Bytes()), proofBytes)
+	if err := result.Err(); err != nil {
+		return nil, verificationFailedf("storage proof verification failed: %v", err)
+	}
+
+	frame := tui.NewProofFrame("Storage slot "+derivation.Expr, result, proofBytes)
+	frame.Decode = exploreValueLines(render.ValueStorage)
+	return frame, nil
+}
+
+func exploreBlockTrie(client *geth.Client, kind string) error {
+	block, err := client.GetBlockByNumber(context.Background(), blockHeight)
+	if err != nil {
+		return rpcFailuref("failed to get block %d: %w", blockHeight, err)
+	}
+
+	var (
+		localTrie    *trie.Trie
+		expectedRoot common.Hash
+		frame        *tui.Frame
+	)
+	if kind == "tx" {
+		localTrie = trie.DeriveTrie(block.Transactions())
+		expectedRoot = block.Header().TxHash
+		frame = tui.NewTrieFrame(fmt.Sprintf("Transactions #%d", blockHeight), localTrie.Root())
+		frame.Decode = exploreValueLines(render.ValueTransaction)
+	} else {
+		blockReceipts, err := client.GetBlockReceipts(context.Background(), blockHeight)
+		if err != nil {
+			return rpcFailuref("failed to get block receipts for block %d: %w", blockHeight, err)
+		}
+		localTrie = trie.DeriveTrie(types.Receipts(blockReceipts))
+		expectedRoot = block.Header().ReceiptHash
+		frame = tui.NewTrieFrame(fmt.Sprintf("Receipts #%d", blockHeight), localTrie.Root())
+		frame.Decode = exploreValueLines(render.ValueReceipt)
+	}
+
+	if localTrie.Hash() != expectedRoot {
+		return verificationFailedf("%s root mismatch: header %s, local trie %s", kind, expectedRoot.Hex(), localTrie.Hash().Hex())
+	}
+	return tui.NewExplorer(frame).Run()
+}
+
+func exploreValueLines(kind render.ValueKind) func([]byte) []string {
+	return func(value []byte) []string {
+		lines := render.ValueLines(kind, value, "  ")
+		if kind == render.ValueAccount {
+			lines = append(lines, "  (press s to open a storage slot)")
+		}
+		return lines
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exploreCmd)
+	exploreCmd.Flags().StringVar(&exploreTrie, "trie", "state", "Trie to explore: state, tx or receipt")
+	exploreCmd.Flags().StringVar(&exploreSlotStr, "slot", "", "Open this account storage slot on start (state only)")
+	_ = exploreCmd.MarkFlagRequired("block-height")
+}
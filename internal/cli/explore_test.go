@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+// TestExploreRejectsBeforeOpening covers the checks that run before the
+// terminal UI is opened.
+func TestExploreRejectsBeforeOpening(t *testing.T) {
+	address := common.HexToAddress("0x000000000000000000000000000000000000cafe")
+	other := common.HexToAddress("0x000000000000000000000000000000000000beef")
+	account, err := rlp.EncodeToBytes(&trie.Account{Balance: new(big.Int), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherLeaf := leafProof(t, other.Bytes(), account)
+	stateRoot := crypto.Keccak256Hash(otherLeaf)
+	txs := testTransactions(t, 1)
+	txRoot := types.DeriveSha(txs, gethtrie.NewStackTrie(nil))
+
+	tests := []struct {
+		name      string
+		trie      string
+		slot      string
+		stateRoot common.Hash
+		txRoot    common.Hash
+		verdict   Verdict
+	}{
+		{"unknown trie", "storage", "", stateRoot, txRoot, VerdictInvalidInput},
+		{"slot on an absent account", "state", "0", stateRoot, txRoot, VerdictInvalidInput},
+		{"account proof not under state root", "state", "", common.HexToHash("0x01"), txRoot, VerdictVerificationFailed},
+		{"tx root mismatch", "tx", "", stateRoot, common.HexToHash("0x01"), VerdictVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := &types.Header{
+				Number:      big.NewInt(3),
+				Root:        tt.stateRoot,
+				UncleHash:   types.EmptyUncleHash,
+				TxHash:      tt.txRoot,
+				ReceiptHash: types.EmptyReceiptsHash,
+				Difficulty:  new(big.Int),
+			}
+			newTestNode(t, map[string]interface{}{
+				"eth_getBlockByNumber": blockResult(t, header, txs),
+				"eth_getProof": map[string]interface{}{
+					"address":      address,
+					"accountProof": []string{hexutil.Encode(otherLeaf)},
+					"balance":      "0x0",
+					"codeHash":     common.Hash{},
+					"nonce":        "0x0",
+					"storageHash":  common.Hash{},
+					"storageProof": []interface{}{},
+				},
+			})
+			accountAddress, blockHeight, exploreTrie, exploreSlotStr = address.Hex(), 3, tt.trie, tt.slot
+
+			_, _, err := runReport(t, runExploreCommand)
+			if verdict := verdictOf(err); verdict != tt.verdict {
+				t.Fatalf("verdict %s (%v), want %s", verdict, err, tt.verdict)
+			}
+		})
+	}
+}
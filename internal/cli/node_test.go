@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newTestNode serves canned JSON-RPC results by method name and points
+// --rpc-url at it for the duration of the test.
+func newTestNode(t *testing.T, results map[string]interface{}) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if result, ok := results[req.Method]; ok {
+			resp["result"] = result
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method " + req.Method + " not found"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	previous := rpcURL
+	rpcURL = server.URL
+	t.Cleanup(func() { rpcURL = previous })
+}
+
+// blockResult is the eth_getBlockByNumber result for header with the given
+// transactions.
+func blockResult(t *testing.T, header *types.Header, txs types.Transactions) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block map[string]interface{}
+	if err := json.Unmarshal(data, &block); err != nil {
+		t.Fatal(err)
+	}
+	block["transactions"] = txs
+	if txs == nil {
+		block["transactions"] = []interface{}{}
+	}
+	block["uncles"] = []interface{}{}
+	return block
+}
+
+// captureStdout runs fn and returns what it printed.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		done <- buf.Bytes()
+	}()
+
+	runErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	return string(<-done), runErr
+}
+
+// runReport runs a command against a fresh report and returns the report
+// along with what the command printed.
+func runReport(t *testing.T, run func(report *Report) error) (*Report, string, error) {
+	t.Helper()
+	report := &Report{}
+	out, err := captureStdout(t, func() error { return run(report) })
+	return report, out, err
+}
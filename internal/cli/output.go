@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/inchori/gethtried/internal/layout"
+	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/slot"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+const (
+	outputText    = "text"
+	outputJSON    = "json"
+	outputNDJSON  = "ndjson"
+	outputDOT     = "dot"
+	outputMermaid = "mermaid"
+	outputHTML    = "html"
+)
+
+const (
+	viewLogical  = "logical"
+	viewRLP      = "rlp"
+	viewPhysical = "physical"
+)
+
+var (
+	outputFormat  string
+	collapseWidth int
+	pathView      string
+	fullBranches  bool
+)
+
+type Roots struct {
+	Header     common.Hash `json:"header"`
+	Calculated common.Hash `json:"calculated"`
+	Local      common.Hash `json:"local"`
+	Match      bool        `json:"match"`
+}
+
+type Item struct {
+	Index  int         `json:"index"`
+	TxHash common.Hash `json:"txHash"`
+	Status *uint64     `json:"status,omitempty"`
+}
+
+type StorageRootCheck struct {
+	Account common.Hash `json:"account"`
+	RPC     common.Hash `json:"rpc"`
+	Match   bool        `json:"match"`
+}
+
+type StorageSlot struct {
+	Slot       common.Hash       `json:"slot"`
+	Expr       string            `json:"expr"`
+	Derivation []slot.Step       `json:"derivation,omitempty"`
+	Value      common.Hash       `json:"value"`
+	Proof      *render.ProofJSON `json:"proof"`
+}
+
+type VariableProof struct {
+	Slot  common.Hash       `json:"slot"`
+	Value common.Hash       `json:"value"`
+	Proof *render.ProofJSON `json:"proof"`
+}
+
+type VariableJSON struct {
+	Expr   string          `json:"expr"`
+	Type   string          `json:"type"`
+	Value  *layout.Value   `json:"value,omitempty"`
+	Proofs []VariableProof `json:"proofs,omitempty"`
+}
+
+type Report struct {
+	Schema           string              `json:"schema"`
+	Command          string              `json:"command"`
+	Verdict          Verdict             `json:"verdict"`
+	Error            string              `json:"error,omitempty"`
+	Block            *render.BlockJSON   `json:"block,omitempty"`
+	Account          *common.Address     `json:"account,omitempty"`
+	AccountProof     *render.ProofJSON   `json:"accountProof,omitempty"`
+	AccountValue     *render.AccountJSON `json:"accountValue,omitempty"`
+	StorageRootCheck *StorageRootCheck   `json:"storageRootCheck,omitempty"`
+	Slot             hexutil.Bytes       `json:"slot,omitempty"`
+	SlotDerivation   []slot.Step         `json:"slotDerivation,omitempty"`
+	StorageProof     *render.ProofJSON   `json:"storageProof,omitempty"`
+	StorageValue     hexutil.Bytes       `json:"storageValue,omitempty"`
+	StorageSlots     []StorageSlot       `json:"storageSlots,omitempty"`
+	Variable         *VariableJSON       `json:"variable,omitempty"`
+	Proxy            *ProxyJSON          `json:"proxy,omitempty"`
+	Roots            *Roots              `json:"roots,omitempty"`
+	Items            []Item              `json:"items,omitempty"`
+	Index            *int                `json:"index,omitempty"`
+	Proof            *render.ProofJSON   `json:"proof,omitempty"`
+	Transaction      *types.Transaction  `json:"transaction,omitempty"`
+	Receipt          *render.ReceiptJSON `json:"receipt,omitempty"`
+
+	graphs []render.Graph
+}
+
+func (r *Report) addProofGraph(title string, result *trie.VerificationResult) {
+	r.graphs = append(r.graphs, render.Graph{Title: title, Result: result})
+}
+
+func (r *Report) addTrieGraph(title string, root trie.Node) {
+	r.graphs = append(r.graphs, render.Graph{Title: title, Root: root})
+}
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON, outputNDJSON, outputDOT, outputMermaid, outputHTML:
+		return nil
+	}
+	return invalidInputf("invalid output format: %s (expected one of: text, json, ndjson, dot, mermaid, html)", outputFormat)
+}
+
+func validateView() error {
+	switch pathView {
+	case viewLogical, viewRLP, viewPhysical:
+		return nil
+	}
+	return invalidInputf("invalid view: %s (expected one of: logical, rlp, physical)", pathView)
+}
+
+func renderPath(result *trie.VerificationResult, proof [][]byte, kind render.ValueKind) {
+	if !textOutput() {
+		return
+	}
+	switch pathView {
+	case viewRLP:
+		render.RenderRLPPath(result)
+	case viewPhysical:
+		render.RenderPhysicalPath(result, proof)
+	default:
+		render.RenderLogicalPath(result, kind, fullBranches)
+	}
+}
+
+func textOutput() bool {
+	return outputFormat == outputText
+}
+
+func printf(format string, args ...interface{}) {
+	if textOutput() {
+		fmt.Printf(format, args...)
+	}
+}
+
+func printLine(args ...interface{}) {
+	if textOutput() {
+		fmt.Println(args...)
+	}
+}
+
+func writeReport(report *Report) error {
+	switch outputFormat {
+	case outputJSON:
+		return render.WriteJSON(os.Stdout, report, false)
+	case outputDOT:
+		if len(report.graphs) == 0 {
+			return nil
+		}
+		return render.WriteDOT(os.Stdout, report.graphs)
+	case outputMermaid:
+		if len(report.graphs) == 0 {
+			return nil
+		}
+		return render.WriteMermaid(os.Stdout, report.graphs, collapseWidth)
+	case outputHTML:
+		title := fmt.Sprintf("gethtried %s", report.Command)
+		if report.Block != nil {
+			title += fmt.Sprintf(" - block %d", report.Block.Number)
+		}
+		return render.WriteHTML(os.Stdout, title, report.graphs)
+	}
+
+	items := report.Items
+	report.Items = nil
+	for _, item := range items {
+		line := struct {
+			Schema  string `json:"schema"`
+			Command string `json:"command"`
+			Kind    string `json:"kind"`
+			Item
+		}{report.Schema, report.Command, "item", item}
+		if err := render.WriteJSON(os.Stdout, line, true); err != nil {
+			return err
+		}
+	}
+
+	slots := report.StorageSlots
+	report.StorageSlots = nil
+	for _, storageSlot := range slots {
+		line := struct {
+			Schema  string `json:"schema"`
+			Command string `json:"command"`
+			Kind    string `json:"kind"`
+			StorageSlot
+		}{report.Schema, report.Command, "slot", storageSlot}
+		if err := render.WriteJSON(os.Stdout, line, true); err != nil {
+			return err
+		}
+	}
+	return render.WriteJSON(os.Stdout, report, true)
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/inchori/gethtried/internal/render"
+)
+
+func TestReportJSONSchema(t *testing.T) {
+	index := 0
+	tests := []struct {
+		name   string
+		report *Report
+		keys   []string
+	}{
+		{
+			name:   "verified",
+			report: &Report{Schema: render.SchemaVersion, Command: "tx", Verdict: VerdictVerified},
+			keys:   []string{"command", "schema", "verdict"},
+		},
+		{
+			name:   "failed",
+			report: &Report{Schema: render.SchemaVersion, Command: "state", Verdict: VerdictRPCFailure, Error: "dial failed"},
+			keys:   []string{"command", "error", "schema", "verdict"},
+		},
+		{
+			name: "transaction proof",
+			report: &Report{
+				Schema:  render.SchemaVersion,
+				Command: "tx",
+				Verdict: VerdictVerified,
+				Roots:   &Roots{},
+				Index:   &index,
+				Proof:   &render.ProofJSON{},
+			},
+			keys: []string{"command", "index", "proof", "roots", "schema", "verdict"},
+		},
+		{
+			name: "storage proof",
+			report: &Report{
+				Schema:           render.SchemaVersion,
+				Command:          "storage",
+				Verdict:          VerdictVerified,
+				Account:          &common.Address{},
+				AccountProof:     &render.ProofJSON{},
+				StorageRootCheck: &StorageRootCheck{},
+				Slot:             common.Hash{}.Bytes(),
+				StorageProof:     &render.ProofJSON{},
+			},
+			keys: []string{"account", "accountProof", "command", "schema", "slot", "storageProof", "storageRootCheck", "verdict"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Fatalf("report fields %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/slot"
+	"github.com/inchori/gethtried/internal/trie"
+	"github.com/spf13/cobra"
+)
+
+var proxyBeaconSlotStr string
+
+type namedSlot struct {
+	name string
+	key  common.Hash
+}
+
+var (
+	eip1967Implementation = namedSlot{"EIP-1967 implementation", eip1967Slot("eip1967.proxy.implementation")}
+	eip1967Admin          = namedSlot{"EIP-1967 admin", eip1967Slot("eip1967.proxy.admin")}
+	eip1967Beacon         = namedSlot{"EIP-1967 beacon", eip1967Slot("eip1967.proxy.beacon")}
+	eip1822Proxiable      = namedSlot{"EIP-1822 proxiable", crypto.Keccak256Hash([]byte("PROXIABLE"))}
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Prove the EIP-1967 / EIP-1822 proxy slots of an account and the code hash of its implementation",
+	Run:   runCommand("proxy", runProxyCommand),
+}
+
+type ProxySlot struct {
+	Name  string            `json:"name"`
+	Slot  common.Hash       `json:"slot"`
+	Value common.Hash       `json:"value"`
+	Proof *render.ProofJSON `json:"proof"`
+}
+
+type ProxyAccount struct {
+	Role         string              `json:"role"`
+	Address      common.Address      `json:"address"`
+	AccountProof *render.ProofJSON   `json:"accountProof"`
+	AccountValue *render.AccountJSON `json:"accountValue,omitempty"`
+	Slots        []ProxySlot         `json:"slots,omitempty"`
+}
+
+type ProxyJSON struct {
+	Chain                []ProxyAccount  `json:"chain"`
+	Implementation       *common.Address `json:"implementation,omitempty"`
+	ImplementationSource string          `json:"implementationSource,omitempty"`
+	HasCode              bool            `json:"hasCode"`
+}
+
+type provenSlot struct {
+	namedSlot
+	value  common.Hash
+	result *trie.VerificationResult
+	proof  [][]byte
+}
+
+type provenAccount struct {
+	role    string
+	address common.Address
+	account trie.Account
+	result  *trie.VerificationResult
+	proof   [][]byte
+	slots   []provenSlot
+}
+
+func runProxyCommand(report *Report) error {
+	if !common.IsHexAddress(accountAddress) {
+		return invalidInputf("invalid account address format: %s (expected format: 0x...)", accountAddress)
+	}
+
+	if blockHeight < 0 {
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
+	}
+
+	beaconSlot, err := parseStorageSlot(proxyBeaconSlotStr)
+	if err != nil {
+		return err
+	}
+
+	client, err := geth.NewEthClient(rpcURL)
+	if err != nil {
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+	}
+
+	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
+	if err != nil {
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
+	}
+
+	if uint64(blockHeight) > latestBlock.NumberU64() {
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+	}
+
+	header, err := client.GetHeaderByNumber(context.Background(), blockHeight)
+	if err != nil {
+		return rpcFailuref("failed to get block header %d: %w", blockHeight, err)
+	}
+
+	address := common.HexToAddress(accountAddress)
+	report.Block = render.NewBlockJSON(header)
+	report.Account = &address
+	report.Proxy = &ProxyJSON{}
+
+	printf("--- Proxy Chain of Trust ---\n")
+	printf("[1] Block Header #%d\n", header.Number.Uint64())
+	printf("    - Block Hash: %s\n", header.Hash().Hex())
+	printf("    - State Root: %s\n", header.Root.Hex())
+
+	var chain []*provenAccount
+	defer func() { renderProxyChain(chain) }()
+
+	proxy, err := proveAccount(client, header.Root, "proxy", address, []namedSlot{eip1967Implementation, eip1967Admin, eip1967Beacon, eip1822Proxiable}, report)
+	chain = append(chain, proxy)
+	if err != nil {
+		return err
+	}
+	report.AccountProof = render.NewProofJSON(proxy.result, proxy.proof)
+	if proxy.result.Exists() {
+		report.AccountValue = render.NewAccountJSON(&proxy.account)
+	}
+
+	var (
+		implementation common.Address
+		source         string
+	)
+	for _, s := range []provenSlot{proxy.slots[0], proxy.slots[3]} {
+		target, ok := slotAddress(s.value)
+		if !ok {
+			continue
+		}
+		if source == "" {
+			implementation, source = target, s.name
+		} else if target != implementation {
+			printf("    WARNING: %s points to %s but %s points to %s\n", source, implementation.Hex(), s.name, target.Hex())
+		}
+	}
+
+	if beaconAddress, ok := slotAddress(proxy.slots[2].value); ok {
+		beacon, err := proveAccount(client, header.Root, "beacon", beaconAddress, []namedSlot{{"implementation (" + beaconSlot.Expr + ")", beaconSlot.Key}}, report)
+		chain = append(chain, beacon)
+		if err != nil {
+			return err
+		}
+		if !hasCode(beacon) {
+			printf("    WARNING: beacon %s has no code\n", beaconAddress.Hex())
+		}
+
+		target, ok := slotAddress(beacon.slots[0].value)
+		switch {
+		case !ok:
+			printf("    WARNING: beacon slot %s does not hold an address (use --beacon-slot for non-standard beacons)\n", beaconSlot.Key.Hex())
+		case source != "":
+			printf("    WARNING: proxy sets both %s and a beacon; using %s\n", source, source)
+		default:
+			implementation, source = target, "beacon "+beaconAddress.Hex()
+		}
+	}
+
+	if source == "" {
+		printf("\nNo proxy slots are set: %s is not an EIP-1967 or EIP-1822 proxy at block %d\n", address.Hex(), blockHeight)
+		return nil
+	}
+
+	impl, err := proveAccount(client, header.Root, "implementation", implementation, nil, report)
+	chain = append(chain, impl)
+	if err != nil {
+		return err
+	}
+	report.Proxy.Implementation = &implementation
+	report.Proxy.ImplementationSource = source
+	report.Proxy.HasCode = hasCode(impl)
+
+	printf("\nImplementation: %s (from %s)\n", implementation.Hex(), source)
+	if !report.Proxy.HasCode {
+		printf("WARNING: implementation has no code at block %d\n", blockHeight)
+		return nil
+	}
+	printf("Code Hash:      %s (verified in the state trie)\n", impl.account.CodeHash.Hex())
+	return nil
+}
+
+// proveAccount fetches the account proof of address, and the storage proofs
+// of slots if any, in one eth_getProof call and verifies them against
+// stateRoot. The returned account is non-nil whenever a proof was fetched so
+// that failed paths can still be rendered.
+func proveAccount(client *geth.Client, stateRoot common.Hash, role string, address common.Address, slots []namedSlot, report *Report) (*provenAccount, error) {
+	keys := make([]common.Hash, len(slots))
+	for i, s := range slots {
+		keys[i] = s.key
+	}
+	proofResult, err := client.GetStorageProof(context.Background(), address.Hex(), keys, blockHeight)
+	if err != nil {
+		return nil, proofFetchErrorf("failed to get proof for %s %s at block %d: %w", role, address.Hex(), blockHeight, err)
+	}
+	if len(proofResult.StorageProof) != len(slots) {
+		return nil, rpcFailuref("expected %d storage proofs for %s, RPC returned %d", len(slots), address.Hex(), len(proofResult.StorageProof))
+	}
+
+	accountProofBytes, err := decodeProof(proofResult.AccountProof)
+	if err != nil {
+		return nil, err
+	}
+	proven := &provenAccount{
+		role:    role,
+		address: address,
+		result:  trie.VerifyProof(stateRoot, crypto.Keccak256(address.Bytes()), accountProofBytes),
+		proof:   accountProofBytes,
+	}
+	entry := ProxyAccount{Role: role, Address: address, AccountProof: render.NewProofJSON(proven.result, accountProofBytes)}
+	report.addProofGraph(fmt.Sprintf("%s Account", roleTitle(role)), proven.result)
+	defer func() { report.Proxy.Chain = append(report.Proxy.Chain, entry) }()
+
+	printf("[%d] State Root -> %s %s (%d proof nodes)\n", len(report.Proxy.Chain)+2, roleTitle(role), address.Hex(), len(accountProofBytes))
+	if err := proven.result.Err(); err != nil {
+		printf("    ACCOUNT PROOF VERIFICATION FAILED: %v\n", err)
+		return proven, verificationFailedf("%s account proof verification failed: %v", role, err)
+	}
+
+	proven.account.Root = types.EmptyRootHash
+	proven.account.CodeHash = types.EmptyCodeHash
+	printf("    ACCOUNT PROOF VERIFICATION SUCCESSFUL\n")
+	if proven.result.Exists() {
+		if err := rlp.DecodeBytes(proven.result.Value, &proven.account); err != nil {
+			return proven, verificationFailedf("failed to decode verified %s account: %w", role, err)
+		}
+		entry.AccountValue = render.NewAccountJSON(&proven.account)
+	} else {
+		printf("    - Account does not exist: verified non-inclusion\n")
+	}
+	printf("    - Storage Root: %s\n", proven.account.Root.Hex())
+	printf("    - Code Hash:    %s\n", proven.account.CodeHash.Hex())
+
+	if len(slots) == 0 {
+		return proven, nil
+	}
+	// Some nodes report a zero storage hash for accounts that do not exist.
+	missingAccount := !proven.result.Exists() && proofResult.StorageHash == (common.Hash{})
+	if proofResult.StorageHash != proven.account.Root && !missingAccount {
+		printf("    STORAGE ROOT MISMATCH: RPC StorageHash %s is not committed to by the verified account\n", proofResult.StorageHash.Hex())
+		return proven, verificationFailedf("storage root mismatch for %s: account commits to %s but RPC returned %s", address.Hex(), proven.account.Root.Hex(), proofResult.StorageHash.Hex())
+	}
+
+	for i, s := range slots {
+		proofBytes, err := decodeProof(proofResult.StorageProof[i].Proof)
+		if err != nil {
+			return proven, err
+		}
+		result := trie.VerifyProof(proven.account.Root, crypto.Keccak256(s.key.Bytes()), proofBytes)
+		report.addProofGraph(fmt.Sprintf("%s %s", roleTitle(role), s.name), result)
+		proven.slots = append(proven.slots, provenSlot{namedSlot: s, result: result, proof: proofBytes})
+		entry.Slots = append(entry.Slots, ProxySlot{Name: s.name, Slot: s.key, Proof: render.NewProofJSON(result, proofBytes)})
+
+		if err := result.Err(); err != nil {
+			printf("    - %s: STORAGE PROOF VERIFICATION FAILED: %v\n", s.name, err)
+			return proven, verificationFailedf("%s storage proof verification failed for %s: %v", role, s.name, err)
+		}
+		if result.Exists() {
+			value, err := trie.DecodeStorageValue(result.Value)
+			if err != nil {
+				return proven, verificationFailedf("invalid storage value in verified leaf for %s: %w", s.name, err)
+			}
+			proven.slots[i].value = value
+			entry.Slots[i].Value = value
+		}
+
+		printf("    - %s\n", s.name)
+		printf("      Slot:  %s (%d proof nodes)\n", s.key.Hex(), len(proofBytes))
+		printf("      Value: %s\n", describeProxySlot(proven.slots[i].value))
+	}
+	return proven, nil
+}
+
+func renderProxyChain(chain []*provenAccount) {
+	for _, proven := range chain {
+		if proven == nil {
+			continue
+		}
+		printf("\n--- %s Account Path: %s ---\n", roleTitle(proven.role), proven.address.Hex())
+		renderPath(proven.result, proven.proof, render.ValueAccount)
+		for _, s := range proven.slots {
+			printf("\n--- %s Storage Path: %s ---\n", roleTitle(proven.role), s.name)
+			renderPath(s.result, s.proof, render.ValueStorage)
+		}
+	}
+}
+
+func eip1967Slot(name string) common.Hash {
+	return slot.Add(crypto.Keccak256Hash([]byte(name)), big.NewInt(-1))
+}
+
+func slotAddress(value common.Hash) (common.Address, bool) {
+	if value == (common.Hash{}) || new(big.Int).Rsh(value.Big(), 160).Sign() != 0 {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(value.Bytes()), true
+}
+
+func describeProxySlot(value common.Hash) string {
+	if value == (common.Hash{}) {
+		return "empty"
+	}
+	if address, ok := slotAddress(value); ok {
+		return address.Hex()
+	}
+	return value.Hex() + " (not an address)"
+}
+
+func hasCode(proven *provenAccount) bool {
+	return proven.result.Exists() && proven.account.CodeHash != types.EmptyCodeHash
+}
+
+func roleTitle(role string) string {
+	switch role {
+	case "proxy":
+		return "Proxy"
+	case "beacon":
+		return "Beacon"
+	}
+	return "Implementation"
+}
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+	proxyCmd.Flags().StringVar(&proxyBeaconSlotStr, "beacon-slot", "1", "Slot of the implementation address in the beacon contract (1 for OpenZeppelin UpgradeableBeacon)")
+	_ = proxyCmd.MarkFlagRequired("block-height")
+	_ = proxyCmd.MarkFlagRequired("account-address")
+}
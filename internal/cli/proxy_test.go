@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProxySlots(t *testing.T) {
+	// Slots published in EIP-1967 and EIP-1822.
+	tests := []struct {
+		slot namedSlot
+		want string
+	}{
+		{eip1967Implementation, "0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"},
+		{eip1967Admin, "0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"},
+		{eip1967Beacon, "0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50"},
+		{eip1822Proxiable, "0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"},
+	}
+	for _, tt := range tests {
+		if tt.slot.key != common.HexToHash(tt.want) {
+			t.Errorf("%s slot %s, want %s", tt.slot.name, tt.slot.key.Hex(), tt.want)
+		}
+	}
+}
+
+func TestDescribeProxySlot(t *testing.T) {
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name    string
+		value   common.Hash
+		address common.Address
+		ok      bool
+		want    string
+	}{
+		{"empty", common.Hash{}, common.Address{}, false, "empty"},
+		{"address", common.BytesToHash(address.Bytes()), address, true, address.Hex()},
+		{"high bits set", common.HexToHash("0x0100000000000000000000000000000000000000aa"), common.Address{}, false, "(not an address)"},
+		{"full word", common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"), common.Address{}, false, "(not an address)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := slotAddress(tt.value)
+			if got != tt.address || ok != tt.ok {
+				t.Fatalf("slotAddress = %s, %t, want %s, %t", got.Hex(), ok, tt.address.Hex(), tt.ok)
+			}
+			if desc := describeProxySlot(tt.value); !strings.Contains(desc, tt.want) {
+				t.Fatalf("description %q, want one containing %q", desc, tt.want)
+			}
+		})
+	}
+}
@@ -2,74 +2,193 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
 	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/trie"
 	"github.com/spf13/cobra"
 )
 
+var (
+	receiptIndex      int
+	receiptTxHashStr  string
+	receiptExportPath string
+)
+
+type receiptProofExport struct {
+	BlockNumber  uint64          `json:"blockNumber"`
+	BlockHash    common.Hash     `json:"blockHash"`
+	ReceiptsRoot common.Hash     `json:"receiptsRoot"`
+	Index        int             `json:"index"`
+	TxHash       common.Hash     `json:"txHash"`
+	Key          hexutil.Bytes   `json:"key"`
+	Proof        []hexutil.Bytes `json:"proof"`
+	Value        hexutil.Bytes   `json:"value"`
+}
+
 var receiptCmd = &cobra.Command{
 	Use:   "receipt",
 	Short: "Visualize the transaction receipt trie for a specific block height",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := runReceiptCommand(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+	Run:   runCommand("receipt", runReceiptCommand),
 }
 
-func runReceiptCommand() error {
+func runReceiptCommand(report *Report) error {
 	if blockHeight < 0 {
-		return fmt.Errorf("block height must be non-negative, got: %d", blockHeight)
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
 	}
 
 	client, err := geth.NewEthClient(rpcURL)
 	if err != nil {
-		return fmt.Errorf("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
 	}
 
 	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block (check RPC connection): %w", err)
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
 	}
 
 	if uint64(blockHeight) > latestBlock.NumberU64() {
-		return fmt.Errorf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
 	}
 
 	block, err := client.GetBlockByNumber(context.Background(), blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get block %d: %w", blockHeight, err)
+		return rpcFailuref("failed to get block %d: %w", blockHeight, err)
 	}
 
 	expectedRoot := block.Header().ReceiptHash
 
 	blockReceipts, err := client.GetBlockReceipts(context.Background(), blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get block receipts for block %d: %w", blockHeight, err)
+		return rpcFailuref("failed to get block receipts for block %d: %w", blockHeight, err)
 	}
 
 	var receipts types.Receipts = blockReceipts
-	fmt.Printf("Successfully fetched %d receipts for block %d.\n", len(receipts), blockHeight)
+	printf("Successfully fetched %d receipts for block %d.\n", len(receipts), blockHeight)
+
+	calculatedRoot := types.DeriveSha(receipts, gethtrie.NewStackTrie(nil))
+	receiptTrie := trie.DeriveTrie(receipts)
+
+	printf("Block Header ReceiptRoot: %s\n", expectedRoot.Hex())
+	printf("Calculated ReceiptRoot:   %s\n", calculatedRoot.Hex())
+	printf("Local Trie ReceiptRoot:   %s\n", receiptTrie.Hash().Hex())
+	rootsMatch := expectedRoot == calculatedRoot && expectedRoot == receiptTrie.Hash()
+	report.Block = render.NewBlockJSON(block.Header())
+	report.Roots = &Roots{
+		Header:     expectedRoot,
+		Calculated: calculatedRoot,
+		Local:      receiptTrie.Hash(),
+		Match:      rootsMatch,
+	}
+	if rootsMatch {
+		printLine("Verification Successful!")
+	} else {
+		printLine("Verification FAILED!")
+	}
 
-	calculatedRoot := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	if receiptIndex < 0 && receiptTxHashStr == "" {
+		printLine("\n--- Receipts in Trie (Key: RLP(index)) ---")
+		for i, r := range receipts {
+			printf("  [Idx %d] TxHash: %s, Status: %d\n", i, r.TxHash.Hex(), r.Status)
+			status := r.Status
+			report.Items = append(report.Items, Item{Index: i, TxHash: r.TxHash, Status: &status})
+		}
 
-	fmt.Printf("Block Header ReceiptRoot: %s\n", expectedRoot.Hex())
-	fmt.Printf("Calculated ReceiptRoot:   %s\n", calculatedRoot.Hex())
+		printLine()
+		if textOutput() {
+			render.RenderTrie(receiptTrie.Root())
+		}
+		report.addTrieGraph("Receipt Trie", receiptTrie.Root())
+		if !rootsMatch {
+			return verificationFailedf("receipt root mismatch: header %s, DeriveSha %s, local trie %s", expectedRoot.Hex(), calculatedRoot.Hex(), receiptTrie.Hash().Hex())
+		}
+		return nil
+	}
 
-	fmt.Println("\n--- Receipts in Trie (Key: RLP(index)) ---")
+	txHashes := make([]common.Hash, len(receipts))
 	for i, r := range receipts {
-		fmt.Printf("  [Idx %d] TxHash: %s, Status: %d\n", i, r.TxHash.Hex(), r.Status)
+		txHashes[i] = r.TxHash
+	}
+
+	index, err := resolveTxIndex(txHashes, receiptIndex, receiptTxHashStr)
+	if err != nil {
+		return err
 	}
 
+	key := rlp.AppendUint64(nil, uint64(index))
+	proof := receiptTrie.Prove(key)
+
+	printf("\n--- Receipt Inclusion Proof ---\n")
+	printf("Index:       %d\n", index)
+	printf("TxHash:      %s\n", receipts[index].TxHash.Hex())
+	printf("Key:         %s (RLP(index))\n", hexutil.Encode(key))
+	printf("Proof Nodes: %d\n", len(proof))
+
+	result := trie.VerifyProof(expectedRoot, key, proof)
+	report.Index = &index
+	report.Proof = render.NewProofJSON(result, proof)
+	report.addProofGraph("Receipt Proof", result)
+	if err := result.Err(); err != nil {
+		printf("PROOF VERIFICATION FAILED: %v\n", err)
+	} else if !result.Exists() {
+		printf("PROOF VERIFICATION FAILED: receipt is not included under header ReceiptRoot\n")
+	} else {
+		printf("PROOF VERIFICATION SUCCESSFUL\n")
+		var verifiedReceipt types.Receipt
+		if err := verifiedReceipt.UnmarshalBinary(result.Value); err != nil {
+			return verificationFailedf("failed to decode verified receipt: %w", err)
+		}
+		report.Receipt = render.NewReceiptJSON(&verifiedReceipt)
+	}
+
+	printf("\n--- Receipt Trie Path Visualization ---\n")
+	renderPath(result, proof, render.ValueReceipt)
+
+	if err := inclusionProofError(result, "receipt"); err != nil {
+		if receiptExportPath != "" {
+			printf("\nReceipt proof not exported: only verified inclusion proofs are written\n")
+		}
+		return err
+	}
+
+	if receiptExportPath != "" {
+		export := receiptProofExport{
+			BlockNumber:  block.NumberU64(),
+			BlockHash:    block.Hash(),
+			ReceiptsRoot: expectedRoot,
+			Index:        index,
+			TxHash:       receipts[index].TxHash,
+			Key:          key,
+			Value:        result.Value,
+		}
+		for _, node := range proof {
+			export.Proof = append(export.Proof, node)
+		}
+
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode receipt proof: %w", err)
+		}
+		if err := os.WriteFile(receiptExportPath, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write receipt proof to %s: %w", receiptExportPath, err)
+		}
+		printf("\nReceipt proof exported to %s\n", receiptExportPath)
+	}
 	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(receiptCmd)
+	receiptCmd.Flags().IntVar(&receiptIndex, "index", -1, "Receipt index to prove")
+	receiptCmd.Flags().StringVar(&receiptTxHashStr, "tx-hash", "", "Transaction hash whose receipt to prove (0x-prefixed)")
+	receiptCmd.Flags().StringVar(&receiptExportPath, "export", "", "Write the receipt proof as JSON to this file")
 	_ = receiptCmd.MarkFlagRequired("block-height")
 }
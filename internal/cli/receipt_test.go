@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+)
+
+func TestReceiptIndexProof(t *testing.T) {
+	txs := testTransactions(t, 2)
+	receipts := make(types.Receipts, len(txs))
+	for i, tx := range txs {
+		receipts[i] = &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs:              []*types.Log{{Address: *tx.To(), Topics: []common.Hash{common.HexToHash("0x01")}, Data: []byte{byte(i)}}},
+			TxHash:            tx.Hash(),
+			GasUsed:           21000,
+		}
+		receipts[i].Bloom = types.CreateBloom(receipts[i])
+	}
+	root := types.DeriveSha(receipts, gethtrie.NewStackTrie(nil))
+
+	tests := []struct {
+		name     string
+		root     common.Hash
+		index    int
+		txHash   string
+		want     string
+		verdict  Verdict
+		exported bool
+	}{
+		{name: "by index", root: root, index: 0, want: "PROOF VERIFICATION SUCCESSFUL", verdict: VerdictVerified, exported: true},
+		{name: "by hash", root: root, index: -1, txHash: txs[1].Hash().Hex(), want: "Index:       1", verdict: VerdictVerified, exported: true},
+		{name: "wrong receipts root", root: common.HexToHash("0x01"), index: 1, want: "Receipt proof not exported", verdict: VerdictVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := &types.Header{
+				Number:      big.NewInt(5),
+				UncleHash:   types.EmptyUncleHash,
+				TxHash:      types.DeriveSha(txs, gethtrie.NewStackTrie(nil)),
+				ReceiptHash: tt.root,
+				Difficulty:  new(big.Int),
+			}
+			newTestNode(t, map[string]interface{}{
+				"eth_getBlockByNumber": blockResult(t, header, txs),
+				"eth_getBlockReceipts": receipts,
+			})
+			path := filepath.Join(t.TempDir(), "receipt-proof.json")
+			blockHeight, receiptIndex, receiptTxHashStr, receiptExportPath = 5, tt.index, tt.txHash, path
+
+			_, out, err := runReport(t, runReceiptCommand)
+			if verdict := verdictOf(err); verdict != tt.verdict {
+				t.Fatalf("verdict %s (%v), want %s", verdict, err, tt.verdict)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Fatalf("output does not contain %q:\n%s", tt.want, out)
+			}
+
+			data, err := os.ReadFile(path)
+			if !tt.exported {
+				if err == nil {
+					t.Fatal("unverified receipt proof was exported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var export receiptProofExport
+			if err := json.Unmarshal(data, &export); err != nil {
+				t.Fatal(err)
+			}
+			var receipt types.Receipt
+			if err := receipt.UnmarshalBinary(export.Value); err != nil {
+				t.Fatal(err)
+			}
+			if export.ReceiptsRoot != root || export.TxHash != receipts[export.Index].TxHash || receipt.CumulativeGasUsed != receipts[export.Index].CumulativeGasUsed {
+				t.Fatalf("exported proof does not match receipt %d: %s", export.Index, data)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/inchori/gethtried/internal/render"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +24,70 @@ to fetch and visualize the underlying Merkle Patricia Tries (State, Storage, etc
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(ExitInvalidInput)
 	}
 }
 
+// validateIndex rejects a negative --index. The flag defaults to -1 to mean
+// "list every item", so only an explicitly given value is checked.
+func validateIndex(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("index")
+	if flag == nil || !flag.Changed {
+		return nil
+	}
+	if index, err := cmd.Flags().GetInt("index"); err == nil && index < 0 {
+		return invalidInputf("index must be non-negative, got: %d", index)
+	}
+	return nil
+}
+
+func runCommand(name string, run func(report *Report) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := validateOutputFormat(); err != nil {
+			exitWithError(err)
+		}
+		if err := validateView(); err != nil {
+			exitWithError(err)
+		}
+
+		report := &Report{Schema: render.SchemaVersion, Command: name}
+		err := validateIndex(cmd)
+		if err == nil {
+			err = run(report)
+		}
+
+		report.Verdict = verdictOf(err)
+		if err != nil {
+			report.Error = err.Error()
+		}
+
+		if textOutput() {
+			if err == nil {
+				fmt.Printf("\nVerdict: %s\n", report.Verdict)
+			}
+		} else if writeErr := writeReport(report); writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write %s output: %v\n", outputFormat, writeErr)
+			os.Exit(ExitError)
+		}
+
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Verdict: %s\n", verdictOf(err))
+	os.Exit(exitCode(err))
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&rpcURL, "rpc-url", "http://localhost:8545", "Geth Archive Node RPC URL")
 	rootCmd.PersistentFlags().Int64Var(&blockHeight, "block-height", 0, "Block height (required)")
 	rootCmd.PersistentFlags().StringVar(&accountAddress, "account-address", "", "Account address to inspect (required)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "Output format: text, json, ndjson, dot, mermaid or html")
+	rootCmd.PersistentFlags().StringVar(&pathView, "view", viewLogical, "Path view in text output: logical, rlp or physical")
+	rootCmd.PersistentFlags().BoolVar(&fullBranches, "full-branches", false, "Print all 16 slots of every branch node in the logical view")
+	rootCmd.PersistentFlags().IntVar(&collapseWidth, "collapse", 0, "Collapse hashes and nibble strings longer than this many characters in mermaid output (0 keeps them whole)")
 }
@@ -2,157 +2,117 @@ package cli
 
 import (
 	"context"
-	"encoding/hex"
-	"fmt"
-	"os"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
-	ethtrie "github.com/ethereum/go-ethereum/trie"
 	"github.com/inchori/gethtried/internal/geth"
 	"github.com/inchori/gethtried/internal/render"
 	"github.com/inchori/gethtried/internal/trie"
 	"github.com/spf13/cobra"
 )
 
-type MapDB struct {
-	data map[string][]byte
-}
-
-func (db *MapDB) Get(key []byte) ([]byte, error) {
-	if value, ok := db.data[string(key)]; ok {
-		return value, nil
+func decodeProof(proof []string) ([][]byte, error) {
+	proofBytes := make([][]byte, 0, len(proof))
+	for i, nodeHex := range proof {
+		rawData, err := hexutil.Decode(nodeHex)
+		if err != nil {
+			return nil, rpcFailuref("failed to decode proof node %d: %w", i, err)
+		}
+		proofBytes = append(proofBytes, rawData)
 	}
-	return nil, fmt.Errorf("key not found")
-}
-
-func (db *MapDB) Has(key []byte) (bool, error) {
-	_, ok := db.data[string(key)]
-	return ok, nil
+	return proofBytes, nil
 }
 
 var stateCmd = &cobra.Command{
 	Use:   "state",
 	Short: "Visualize the state trie for a specific account at a specific block height",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := runStateCommand(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+	Run:   runCommand("state", runStateCommand),
 }
 
-func runStateCommand() error {
+func runStateCommand(report *Report) error {
 	if !common.IsHexAddress(accountAddress) {
-		return fmt.Errorf("invalid account address format: %s (expected format: 0x...)", accountAddress)
+		return invalidInputf("invalid account address format: %s (expected format: 0x...)", accountAddress)
 	}
 
 	if blockHeight < 0 {
-		return fmt.Errorf("block height must be non-negative, got: %d", blockHeight)
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
 	}
 
 	client, err := geth.NewEthClient(rpcURL)
 	if err != nil {
-		return fmt.Errorf("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
 	}
 
 	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block (check RPC connection): %w", err)
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
 	}
 
 	if uint64(blockHeight) > latestBlock.NumberU64() {
-		return fmt.Errorf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
 	}
 
 	block, err := client.GetBlockByNumber(context.Background(), blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get block %d: %w", blockHeight, err)
+		return rpcFailuref("failed to get block %d: %w", blockHeight, err)
 	}
 	stateRoot := block.Header().Root
+	report.Block = render.NewBlockJSON(block.Header())
+	address := common.HexToAddress(accountAddress)
+	report.Account = &address
 
 	proofResult, err := client.GetAccountProof(context.Background(), accountAddress, blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get account proof for %s at block %d: %w", accountAddress, blockHeight, err)
+		return proofFetchErrorf("failed to get account proof for %s at block %d: %w", accountAddress, blockHeight, err)
 	}
 
-	fmt.Printf("Successfully got %d proof nodes for %s at block %d.\n", len(proofResult.AccountProof), accountAddress, blockHeight)
-
-	var proofBytes [][]byte
-	var renderNodeList []trie.RenderNodeData
-	var finalValue interface{}
-
-	for i, nodeHexStringBytes := range proofResult.AccountProof {
-		rawData, err := hexutil.Decode(nodeHexStringBytes)
-		if err != nil {
-			return fmt.Errorf("failed to decode proof node %d: %w", i, err)
-		}
-
-		proofBytes = append(proofBytes, rawData)
-		nodeKey := crypto.Keccak256Hash(rawData)
-
-		parsedNode, err := trie.ParseNode(rawData)
-		if err != nil {
-			return fmt.Errorf("failed to parse proof node %d: %w", i, err)
-		}
+	printf("Successfully got %d proof nodes for %s at block %d.\n", len(proofResult.AccountProof), accountAddress, blockHeight)
 
-		renderNodeList = append(renderNodeList, trie.RenderNodeData{
-			Key:  nodeKey,
-			Node: parsedNode,
-		})
-
-		if leafNode, ok := parsedNode.(*trie.LeafNode); ok {
-			var accountData trie.Account
-			if err := rlp.DecodeBytes(leafNode.Value, &accountData); err == nil {
-				finalValue = &accountData
-			}
-		}
+	proofBytes, err := decodeProof(proofResult.AccountProof)
+	if err != nil {
+		return err
 	}
 
 	targetPathHash := crypto.Keccak256(common.HexToAddress(accountAddress).Bytes())
-	targetPathNibbles := hex.EncodeToString(targetPathHash)
 
-	fmt.Printf("\n--- Cryptographic Proof Verification ---\n")
+	printf("\n--- Cryptographic Proof Verification ---\n")
 
-	proofDB := make(map[string][]byte)
-	for _, nodeBytes := range proofBytes {
-		key := crypto.Keccak256Hash(nodeBytes)
-		proofDB[string(key[:])] = nodeBytes
-	}
+	result := trie.VerifyProof(stateRoot, targetPathHash, proofBytes)
+	report.AccountProof = render.NewProofJSON(result, proofBytes)
+	report.addProofGraph("Account Proof", result)
 
-	verifiedValue, err := ethtrie.VerifyProof(stateRoot, targetPathHash, &MapDB{data: proofDB})
-	if err != nil {
-		fmt.Printf("PROOF VERIFICATION FAILED: %v\n", err)
+	if err := result.Err(); err != nil {
+		printf("PROOF VERIFICATION FAILED: %v\n", err)
 	} else {
-		fmt.Printf("PROOF VERIFICATION SUCCESSFUL\n")
+		printf("PROOF VERIFICATION SUCCESSFUL\n")
 
-		if len(verifiedValue) > 0 {
+		if result.Exists() {
 			var verifiedAccount trie.Account
-			if err := rlp.DecodeBytes(verifiedValue, &verifiedAccount); err == nil {
-				fmt.Printf("   Verified Account Data:\n")
-				fmt.Printf("   - Nonce: %d\n", verifiedAccount.Nonce)
-				fmt.Printf("   - Balance: %s wei\n", verifiedAccount.Balance.String())
-				fmt.Printf("   - Storage Root: %s\n", verifiedAccount.Root.Hex())
-				fmt.Printf("   - Code Hash: %s\n", verifiedAccount.CodeHash.Hex())
+			if err := rlp.DecodeBytes(result.Value, &verifiedAccount); err == nil {
+				report.AccountValue = render.NewAccountJSON(&verifiedAccount)
+				printf("   Verified Account Data:\n")
+				printf("   - Nonce: %d\n", verifiedAccount.Nonce)
+				printf("   - Balance: %s wei\n", verifiedAccount.Balance.String())
+				printf("   - Storage Root: %s\n", verifiedAccount.Root.Hex())
+				printf("   - Code Hash: %s\n", verifiedAccount.CodeHash.Hex())
 			} else {
-				fmt.Printf("   Raw verified value: %s\n", hexutil.Encode(verifiedValue))
+				printf("   Raw verified value: %s\n", hexutil.Encode(result.Value))
 			}
 		} else {
-			fmt.Printf("   Account does not exist (empty proof)\n")
+			printf("   Account does not exist: verified non-inclusion\n")
+			printf("   - Reason: %s\n", result.Exclusion.String())
+			printf("   - Supporting Nodes: %d\n", len(result.Steps))
 		}
 	}
 
-	proofMap := make(map[string]trie.RenderNodeData)
-	for _, rn := range renderNodeList {
-		proofMap[rn.Key.String()] = rn
-	}
-
-	startRootKey := renderNodeList[0].Key
-	fmt.Printf("\n--- Trie Path Visualization ---\n")
-	render.RenderLogicalPath(startRootKey, targetPathNibbles, proofMap, finalValue)
+	printf("\n--- Trie Path Visualization ---\n")
+	renderPath(result, proofBytes, render.ValueAccount)
 
+	if err := result.Err(); err != nil {
+		return verificationFailedf("account proof verification failed: %v", err)
+	}
 	return nil
 }
 
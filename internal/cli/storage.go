@@ -2,147 +2,558 @@ package cli
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
 	"math/big"
 	"os"
-	"strconv"
+	"regexp"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	ethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/layout"
 	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/slot"
 	"github.com/inchori/gethtried/internal/trie"
 	"github.com/spf13/cobra"
 )
 
-var storageSlotStr string
+const maxStorageSlots = 256
+
+// slotRangePattern matches start..end ranges. Both ends must be plain slot
+// numbers so a range cannot span arbitrary derived keys.
+var slotRangePattern = regexp.MustCompile(`^\s*(0[xX][0-9a-fA-F]+|[0-9]+)\s*\.\.\s*(0[xX][0-9a-fA-F]+|[0-9]+)\s*$`)
+
+var (
+	storageSlotStrs  []string
+	storageSlotsFile string
+	storageVar       string
+	layoutPath       string
+	layoutPresets    []string
+)
 
 var storageCmd = &cobra.Command{
 	Use:   "storage",
 	Short: "Visualize the storage trie for a specific account at a specific block height",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := runStorageCommand(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+	Run:   runCommand("storage", runStorageCommand),
 }
 
-func runStorageCommand() error {
+func runStorageCommand(report *Report) error {
 	if !common.IsHexAddress(accountAddress) {
-		return fmt.Errorf("invalid account address format: %s (expected format: 0x...)", accountAddress)
+		return invalidInputf("invalid account address format: %s (expected format: 0x...)", accountAddress)
 	}
 
 	if blockHeight < 0 {
-		return fmt.Errorf("block height must be non-negative, got: %d", blockHeight)
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
 	}
 
-	var storageSlot int64
-	if strings.HasPrefix(storageSlotStr, "0x") || strings.HasPrefix(storageSlotStr, "0X") {
-		slotBig, ok := new(big.Int).SetString(storageSlotStr[2:], 16)
-		if !ok {
-			return fmt.Errorf("invalid hex storage slot: %s", storageSlotStr)
-		}
-		if !slotBig.IsInt64() {
-			return fmt.Errorf("storage slot too large: %s (max: %d)", storageSlotStr, int64(^uint64(0)>>1))
-		}
-		storageSlot = slotBig.Int64()
-	} else {
-		var err error
-		storageSlot, err = strconv.ParseInt(storageSlotStr, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid storage slot: %s (must be decimal number or hex with 0x prefix)", storageSlotStr)
-		}
-	}
-
-	if storageSlot < 0 {
-		return fmt.Errorf("storage slot must be non-negative, got: %d", storageSlot)
+	derivations, variable, err := resolveStorageTargets()
+	if err != nil {
+		return err
 	}
 
 	client, err := geth.NewEthClient(rpcURL)
 	if err != nil {
-		return fmt.Errorf("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
 	}
 
 	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block (check RPC connection): %w", err)
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
 	}
 
 	if uint64(blockHeight) > latestBlock.NumberU64() {
-		return fmt.Errorf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
 	}
 
-	storageProof, err := client.GetStorageProof(context.Background(), accountAddress, storageSlot, blockHeight)
+	keys := make([]common.Hash, len(derivations))
+	for i, derivation := range derivations {
+		keys[i] = derivation.Key
+	}
+	storageProof, err := client.GetStorageProof(context.Background(), accountAddress, keys, blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get storage proof for %s slot %d at block %d: %w", accountAddress, storageSlot, blockHeight, err)
+		return proofFetchErrorf("failed to get storage proof for %s at block %d: %w", accountAddress, blockHeight, err)
 	}
 
-	if len(storageProof.StorageProof) == 0 {
-		return fmt.Errorf("no storage proof returned for slot %d (slot may not exist)", storageSlot)
+	if len(storageProof.StorageProof) != len(keys) {
+		return rpcFailuref("expected %d storage proofs, RPC returned %d", len(keys), len(storageProof.StorageProof))
 	}
 
-	storageRoot := storageProof.StorageHash
-	slotKey := common.LeftPadBytes(big.NewInt(storageSlot).Bytes(), 32)
-	targetPathHash := crypto.Keccak256Hash(slotKey)
-	targetPathNibbles := hex.EncodeToString(targetPathHash.Bytes())
+	header, err := client.GetHeaderByNumber(context.Background(), blockHeight)
+	if err != nil {
+		return rpcFailuref("failed to get block header %d: %w", blockHeight, err)
+	}
+
+	address := common.HexToAddress(accountAddress)
+	report.Block = render.NewBlockJSON(header)
+	report.Account = &address
 
-	var proofBytes [][]byte
-	proofMap := make(map[string]trie.RenderNodeData)
-	storagePathNodes := storageProof.StorageProof[0].Proof
-	var finalStorageValue []byte
+	if len(derivations) == 1 {
+		report.Slot = derivations[0].Key.Bytes()
+		report.SlotDerivation = derivations[0].Steps
+		printSlotDerivation(derivations[0])
+	}
+
+	storageRoot, err := verifyStorageAccount(header, storageProof, report)
+	if err != nil {
+		return err
+	}
 
-	for _, nodeHexBytes := range storagePathNodes {
-		rawData, _ := hexutil.Decode(nodeHexBytes)
-		proofBytes = append(proofBytes, rawData)
-		nodeKey := crypto.Keccak256Hash(rawData)
-		parsedNode, _ := trie.ParseNode(rawData)
+	if len(derivations) > 1 {
+		return verifyStorageSlots(derivations, storageRoot, storageProof.StorageProof, report)
+	}
 
-		proofMap[nodeKey.String()] = trie.RenderNodeData{Key: nodeKey, Node: parsedNode}
+	derivation := derivations[0]
+	storageProofBytes, err := decodeProof(storageProof.StorageProof[0].Proof)
+	if err != nil {
+		return verificationFailedf("invalid storage proof: %w", err)
+	}
 
-		if leaf, ok := parsedNode.(*trie.LeafNode); ok {
-			finalStorageValue = leaf.Value
+	printf("[4] Storage Root -> Slot %s (%d proof nodes)\n", derivation.Key.Hex(), len(storageProofBytes))
+	storageResult := trie.VerifyProof(storageRoot, crypto.Keccak256(derivation.Key.Bytes()), storageProofBytes)
+	report.StorageProof = render.NewProofJSON(storageResult, storageProofBytes)
+	report.addProofGraph("Storage Proof", storageResult)
+	if err := storageResult.Err(); err != nil {
+		printf("    STORAGE PROOF VERIFICATION FAILED: %v\n", err)
+	} else {
+		printf("    STORAGE PROOF VERIFICATION SUCCESSFUL\n")
+		if storageResult.Exists() {
+			storageValue, err := trie.DecodeStorageValue(storageResult.Value)
+			if err != nil {
+				return verificationFailedf("invalid storage value in verified leaf: %w", err)
+			}
+			report.StorageValue = storageValue[:]
+			for _, line := range render.ValueLines(render.ValueStorage, storageResult.Value, "    ") {
+				printLine(line)
+			}
+		} else {
+			report.StorageValue = common.Hash{}.Bytes()
+			printf("    - Storage slot is empty: verified non-inclusion\n")
+			printf("    - Reason: %s\n", storageResult.Exclusion.String())
 		}
 	}
 
-	fmt.Printf("\n--- Storage Proof Verification ---\n")
+	printf("\n--- Storage Trie Path Visualization ---\n")
+	renderPath(storageResult, storageProofBytes, render.ValueStorage)
 
-	proofDB := make(map[string][]byte)
-	for _, nodeBytes := range proofBytes {
-		key := crypto.Keccak256Hash(nodeBytes)
-		proofDB[string(key[:])] = nodeBytes
+	if err := storageResult.Err(); err != nil {
+		return verificationFailedf("storage proof verification failed: %v", err)
 	}
 
-	verifiedValue, err := ethtrie.VerifyProof(storageRoot, targetPathHash.Bytes(), &MapDB{data: proofDB})
+	if variable != nil {
+		return decodeStorageVariable(client, variable, storageRoot, common.BytesToHash(report.StorageValue), report)
+	}
+	return nil
+}
+
+// verifyStorageAccount checks the account proof against the block's state
+// root and returns the storage root the account commits to.
+func verifyStorageAccount(header *types.Header, storageProof *gethclient.AccountResult, report *Report) (common.Hash, error) {
+	accountProofBytes, err := decodeProof(storageProof.AccountProof)
 	if err != nil {
-		fmt.Printf("STORAGE PROOF VERIFICATION FAILED: %v\n", err)
+		return common.Hash{}, verificationFailedf("invalid account proof: %w", err)
+	}
+
+	printf("\n--- Chain of Trust Verification ---\n")
+	printf("[1] Block Header #%d\n", header.Number.Uint64())
+	printf("    - Block Hash: %s\n", header.Hash().Hex())
+	printf("    - State Root: %s\n", header.Root.Hex())
+
+	accountPathHash := crypto.Keccak256(common.HexToAddress(accountAddress).Bytes())
+	accountResult := trie.VerifyProof(header.Root, accountPathHash, accountProofBytes)
+	report.AccountProof = render.NewProofJSON(accountResult, accountProofBytes)
+	report.addProofGraph("Account Proof", accountResult)
+
+	printf("[2] State Root -> Account (%d proof nodes)\n", len(accountProofBytes))
+	if err := accountResult.Err(); err != nil {
+		printf("    ACCOUNT PROOF VERIFICATION FAILED: %v\n", err)
+		printf("\n--- Account Trie Path Visualization ---\n")
+		renderPath(accountResult, accountProofBytes, render.ValueAccount)
+		return common.Hash{}, verificationFailedf("account proof verification failed: %v", err)
+	}
+
+	var account trie.Account
+	if accountResult.Exists() {
+		if err := rlp.DecodeBytes(accountResult.Value, &account); err != nil {
+			return common.Hash{}, verificationFailedf("failed to decode verified account: %w", err)
+		}
+		report.AccountValue = render.NewAccountJSON(&account)
 	} else {
-		fmt.Printf("STORAGE PROOF VERIFICATION SUCCESSFUL\n")
-		if len(verifiedValue) > 0 {
-			fmt.Printf("   Storage Value: %s\n", hexutil.Encode(verifiedValue))
-			if len(verifiedValue) == 32 {
-				storageInt := new(big.Int).SetBytes(verifiedValue)
-				fmt.Printf("   As Integer: %s\n", storageInt.String())
+		account.Root = types.EmptyRootHash
+	}
+
+	printf("    ACCOUNT PROOF VERIFICATION SUCCESSFUL\n")
+	if !accountResult.Exists() {
+		printf("    - Account does not exist: verified non-inclusion (empty storage root implied)\n")
+		printf("    - Reason: %s\n", accountResult.Exclusion.String())
+	}
+	printf("    - Account:      %s\n", common.HexToAddress(accountAddress).Hex())
+	printf("    - Storage Root: %s\n", account.Root.Hex())
+
+	storageRoot := storageProof.StorageHash
+	printf("[3] Account -> Storage Root\n")
+	printf("    - Account Storage Root: %s\n", account.Root.Hex())
+	printf("    - RPC StorageHash:      %s\n", storageRoot.Hex())
+	report.StorageRootCheck = &StorageRootCheck{
+		Account: account.Root,
+		RPC:     storageRoot,
+		Match:   account.Root == storageRoot,
+	}
+	if account.Root != storageRoot {
+		printf("    STORAGE ROOT MISMATCH: RPC StorageHash is not committed to by the verified account\n")
+		return common.Hash{}, verificationFailedf("storage root mismatch: account commits to %s but RPC returned %s", account.Root.Hex(), storageRoot.Hex())
+	}
+	printf("    STORAGE ROOT MATCH\n")
+	return account.Root, nil
+}
+
+func verifyStorageSlots(derivations []*slot.Derivation, storageRoot common.Hash, proofs []gethclient.StorageResult, report *Report) error {
+	var (
+		paths     []render.MergedPath
+		allNodes  [][]byte
+		seen      = make(map[common.Hash]bool)
+		failed    int
+		proofSets [][][]byte
+	)
+	for i, derivation := range derivations {
+		proofBytes, err := decodeProof(proofs[i].Proof)
+		if err != nil {
+			return verificationFailedf("invalid storage proof for slot %s: %w", derivation.Key.Hex(), err)
+		}
+		proofSets = append(proofSets, proofBytes)
+		for _, raw := range proofBytes {
+			if hash := crypto.Keccak256Hash(raw); !seen[hash] {
+				seen[hash] = true
+				allNodes = append(allNodes, raw)
 			}
+		}
+	}
+
+	printf("[4] Storage Root -> %d Slots (%d unique proof nodes from one eth_getProof call)\n", len(derivations), len(allNodes))
+	for i, derivation := range derivations {
+		result := trie.VerifyProof(storageRoot, crypto.Keccak256(derivation.Key.Bytes()), proofSets[i])
+		entry := StorageSlot{
+			Slot:       derivation.Key,
+			Expr:       derivation.Expr,
+			Derivation: derivation.Steps,
+			Proof:      render.NewProofJSON(result, proofSets[i]),
+		}
+
+		value := "empty (verified non-inclusion)"
+		switch {
+		case result.Err() != nil:
+			failed++
+			value = "VERIFICATION FAILED: " + result.Err().Error()
+		case result.Exists():
+			word, err := trie.DecodeStorageValue(result.Value)
+			if err != nil {
+				return verificationFailedf("invalid storage value in verified leaf for slot %s: %w", derivation.Key.Hex(), err)
+			}
+			entry.Value = word
+			value = word.Hex()
+		}
+		report.StorageSlots = append(report.StorageSlots, entry)
+		paths = append(paths, render.MergedPath{Label: storageSlotLabel(derivation), Value: value, Result: result})
+
+		printf("    - %s (%d proof nodes)\n", storageSlotLabel(derivation), len(proofSets[i]))
+		printDerivationSteps(derivation, "      ")
+		if derivation.Expr != derivation.Key.Hex() {
+			printf("      Key:   %s\n", derivation.Key.Hex())
+		}
+		printf("      Value: %s\n", value)
+	}
+
+	if failed == 0 {
+		printf("    ALL %d STORAGE PROOFS VERIFIED\n", len(derivations))
+	} else {
+		printf("    %d OF %d STORAGE PROOFS FAILED\n", failed, len(derivations))
+	}
+
+	var fragment trie.Node
+	if storageRoot != types.EmptyRootHash {
+		var err error
+		if fragment, err = trie.ProofFragment(storageRoot, allNodes); err != nil {
+			return verificationFailedf("failed to merge storage proofs: %v", err)
+		}
+		report.addTrieGraph(fmt.Sprintf("Storage Trie Fragment (%d slots)", len(derivations)), fragment)
+	}
+
+	if textOutput() {
+		printf("\n--- Storage Trie Path Visualization ---\n")
+		if pathView == viewLogical {
+			render.RenderMergedPaths(fragment, paths)
 		} else {
-			fmt.Printf("   Storage slot is empty\n")
+			for i, p := range paths {
+				printf("\n[Slot %s]\n", p.Label)
+				renderPath(p.Result, proofSets[i], render.ValueStorage)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return verificationFailedf("%d of %d storage proofs failed verification", failed, len(derivations))
+	}
+	return nil
+}
+
+func storageSlotLabel(derivation *slot.Derivation) string {
+	if derivation.Expr == derivation.Key.Hex() {
+		return derivation.Expr
+	}
+	return fmt.Sprintf("slot %s", derivation.Expr)
+}
+
+type storageVariable struct {
+	layout   *layout.Layout
+	location *layout.Location
+}
+
+func resolveStorageTargets() ([]*slot.Derivation, *storageVariable, error) {
+	hasSlots := len(storageSlotStrs) > 0 || storageSlotsFile != ""
+	if hasSlots == (storageVar != "") {
+		return nil, nil, invalidInputf("exactly one of --slot / --slots-file or --var is required")
+	}
+	if storageVar == "" {
+		derivations, err := expandStorageSlots(storageSlotStrs, storageSlotsFile)
+		return derivations, nil, err
+	}
+
+	storageLayout, err := loadStorageLayout()
+	if err != nil {
+		return nil, nil, err
+	}
+	location, err := storageLayout.Resolve(storageVar)
+	if err != nil {
+		return nil, nil, invalidInputf("invalid variable %q: %v", storageVar, err)
+	}
+
+	derivation := &slot.Derivation{Expr: location.Expr, Key: location.Slot, Steps: location.Steps}
+	return []*slot.Derivation{derivation}, &storageVariable{layout: storageLayout, location: location}, nil
+}
+
+func loadStorageLayout() (*layout.Layout, error) {
+	if layoutPath == "" && len(layoutPresets) == 0 {
+		return nil, invalidInputf("--var requires --layout or --preset")
+	}
+
+	var layouts []*layout.Layout
+	if layoutPath != "" {
+		fileLayout, err := layout.Load(layoutPath)
+		if err != nil {
+			return nil, invalidInputf("failed to load storage layout %s: %v", layoutPath, err)
+		}
+		layouts = append(layouts, fileLayout)
+	}
+	for _, name := range layoutPresets {
+		preset, err := layout.Preset(name)
+		if err != nil {
+			return nil, invalidInputf("%v", err)
+		}
+		layouts = append(layouts, preset)
+	}
+
+	merged, err := layout.Merge(layouts...)
+	if err != nil {
+		return nil, invalidInputf("cannot combine storage layouts: %v", err)
+	}
+	return merged, nil
+}
+
+func decodeStorageVariable(client *geth.Client, variable *storageVariable, storageRoot, primaryValue common.Hash, report *Report) error {
+	location := variable.location
+	typeLabel := location.Type
+	if t, err := variable.layout.Type(location.Type); err == nil {
+		typeLabel = t.Label
+	}
+	report.Variable = &VariableJSON{Expr: location.Expr, Type: typeLabel}
+
+	printf("\n--- Variable %s (%s) ---\n", location.Expr, typeLabel)
+
+	words := map[common.Hash]common.Hash{location.Slot: primaryValue}
+	read := func(key common.Hash) (common.Hash, error) {
+		if word, ok := words[key]; ok {
+			return word, nil
+		}
+
+		storageProof, err := client.GetStorageProof(context.Background(), accountAddress, []common.Hash{key}, blockHeight)
+		if err != nil {
+			return common.Hash{}, proofFetchErrorf("failed to get storage proof for slot %s at block %d: %w", key.Hex(), blockHeight, err)
+		}
+		if len(storageProof.StorageProof) == 0 {
+			return common.Hash{}, rpcFailuref("no storage proof returned for slot %s", key.Hex())
+		}
+		if storageProof.StorageHash != storageRoot {
+			return common.Hash{}, verificationFailedf("storage root mismatch for slot %s: account commits to %s but RPC returned %s", key.Hex(), storageRoot.Hex(), storageProof.StorageHash.Hex())
+		}
+		proofBytes, err := decodeProof(storageProof.StorageProof[0].Proof)
+		if err != nil {
+			return common.Hash{}, verificationFailedf("invalid storage proof for slot %s: %w", key.Hex(), err)
 		}
+
+		result := trie.VerifyProof(storageRoot, crypto.Keccak256(key.Bytes()), proofBytes)
+		if err := result.Err(); err != nil {
+			printf("    - Slot %s: PROOF VERIFICATION FAILED: %v\n", key.Hex(), err)
+			return common.Hash{}, verificationFailedf("storage proof verification failed for slot %s: %v", key.Hex(), err)
+		}
+		var word common.Hash
+		if result.Exists() {
+			if word, err = trie.DecodeStorageValue(result.Value); err != nil {
+				return common.Hash{}, verificationFailedf("invalid storage value in verified leaf for slot %s: %w", key.Hex(), err)
+			}
+		}
+
+		printf("    - Slot %s verified (%d proof nodes): %s\n", key.Hex(), len(proofBytes), word.Hex())
+		report.Variable.Proofs = append(report.Variable.Proofs, VariableProof{
+			Slot:  key,
+			Value: word,
+			Proof: render.NewProofJSON(result, proofBytes),
+		})
+		words[key] = word
+		return word, nil
 	}
 
-	fmt.Printf("\n--- Storage Trie Path Visualization ---\n")
-	render.RenderLogicalPath(storageRoot, targetPathNibbles, proofMap, finalStorageValue)
+	value, err := variable.layout.Decode(location, read)
+	if err != nil {
+		if verdictOf(err) == VerdictError {
+			return verificationFailedf("failed to decode %s: %v", location.Expr, err)
+		}
+		return err
+	}
+	report.Variable.Value = value
 
+	if proofs := len(report.Variable.Proofs); proofs > 0 {
+		printf("    Verified %d additional storage proof(s) against storage root %s\n", proofs, storageRoot.Hex())
+	}
+	for _, line := range render.FormatFields([]render.Field{variableField(*value)}, "    ") {
+		printLine(line)
+	}
 	return nil
 }
 
+func variableField(value layout.Value) render.Field {
+	field := render.Field{Name: value.Label, Value: value.Value}
+	if field.Value == "" {
+		field.Value = value.Type
+	} else if len(value.Members) == 0 {
+		field.Value += " (" + value.Type + ")"
+	}
+	for _, member := range value.Members {
+		field.Fields = append(field.Fields, variableField(member))
+	}
+	return field
+}
+
+// expandStorageSlots parses every --slot value and slots file line, expands
+// numeric start..end ranges key by key and drops duplicate keys.
+func expandStorageSlots(specs []string, file string) ([]*slot.Derivation, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, invalidInputf("failed to read slots file: %v", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if i := strings.IndexByte(line, '#'); i >= 0 {
+				line = line[:i]
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				specs = append(specs, line)
+			}
+		}
+	}
+
+	var derivations []*slot.Derivation
+	seen := make(map[common.Hash]bool)
+	add := func(derivation *slot.Derivation) error {
+		if seen[derivation.Key] {
+			return nil
+		}
+		if len(derivations) == maxStorageSlots {
+			return invalidInputf("too many storage slots (at most %d per call)", maxStorageSlots)
+		}
+		seen[derivation.Key] = true
+		derivations = append(derivations, derivation)
+		return nil
+	}
+
+	for _, spec := range specs {
+		bounds := slotRangePattern.FindStringSubmatch(spec)
+		if bounds == nil {
+			derivation, err := parseStorageSlot(spec)
+			if err != nil {
+				if strings.Contains(spec, "..") {
+					return nil, invalidInputf("invalid slot range %q: both ends must be plain decimal or 0x slot numbers", spec)
+				}
+				return nil, err
+			}
+			if err := add(derivation); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		start, err := parseStorageSlot(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseStorageSlot(bounds[2])
+		if err != nil {
+			return nil, err
+		}
+		count := new(big.Int).Sub(end.Key.Big(), start.Key.Big())
+		if count.Sign() < 0 {
+			return nil, invalidInputf("invalid slot range %q: end is before start", spec)
+		}
+		if !count.IsInt64() || count.Int64() >= maxStorageSlots {
+			return nil, invalidInputf("invalid slot range %q: more than %d slots", spec, maxStorageSlots)
+		}
+		for i := int64(0); i <= count.Int64(); i++ {
+			key := slot.Add(start.Key, big.NewInt(i))
+			expr := key.Hex()
+			if key.Big().IsUint64() {
+				expr = key.Big().String()
+			}
+			if err := add(&slot.Derivation{Expr: expr, Key: key}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return derivations, nil
+}
+
+func parseStorageSlot(slotStr string) (*slot.Derivation, error) {
+	derivation, err := slot.Parse(slotStr)
+	if err != nil {
+		return nil, invalidInputf("invalid storage slot %q: %v", slotStr, err)
+	}
+	return derivation, nil
+}
+
+func printSlotDerivation(derivation *slot.Derivation) {
+	if len(derivation.Steps) == 0 {
+		return
+	}
+	printf("\n--- Slot Derivation: %s ---\n", derivation.Expr)
+	printDerivationSteps(derivation, "  ")
+}
+
+func printDerivationSteps(derivation *slot.Derivation, indent string) {
+	for i, step := range derivation.Steps {
+		printf("%s[%d] %s\n", indent, i+1, step.Expr)
+		printf("%s    = %s\n", indent, step.Formula)
+		printf("%s    = %s\n", indent, step.Slot.Hex())
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(storageCmd)
-	storageCmd.Flags().StringVar(&storageSlotStr, "slot", "0", "Storage slot (decimal or hex with 0x prefix)")
+	storageCmd.Flags().StringArrayVar(&storageSlotStrs, "slot", nil, "Storage slot: a number, a 32-byte key, a derivation such as mapping(0)[0x...], array(5)[7] or field(slot, offset), or a numeric range start..end (repeatable)")
+	storageCmd.Flags().StringVar(&storageSlotsFile, "slots-file", "", "File with one storage slot or range per line (# starts a comment)")
+	storageCmd.Flags().StringVar(&storageVar, "var", "", "Storage variable to read instead of --slot, e.g. owner, balances[0x...] or users[3].name (requires --layout or --preset)")
+	storageCmd.Flags().StringVar(&layoutPath, "layout", "", "Path to the solc/foundry storageLayout JSON used to resolve and decode --var")
+	storageCmd.Flags().StringSliceVar(&layoutPresets, "preset", nil, "Built-in layouts for --var, covering only the ERC-7201 namespaced storage of OpenZeppelin Contracts Upgradeable v5: "+strings.Join(layout.PresetNames(), ", "))
 	_ = storageCmd.MarkFlagRequired("block-height")
 	_ = storageCmd.MarkFlagRequired("account-address")
-	_ = storageCmd.MarkFlagRequired("slot")
 }
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/slot"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+// leafProof returns the only node of a trie holding value under
+// keccak256(key), which is also the whole proof for that key.
+func leafProof(t *testing.T, key, value []byte) []byte {
+	t.Helper()
+	path := append([]byte{0x20}, crypto.Keccak256(key)...)
+	node, err := rlp.EncodeToBytes([][]byte{path, value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestStorageChainOfTrust(t *testing.T) {
+	address := common.HexToAddress("0x000000000000000000000000000000000000cafe")
+	storageLeaf := leafProof(t, common.BigToHash(big.NewInt(2)).Bytes(), []byte{0x2a})
+	storageRoot := crypto.Keccak256Hash(storageLeaf)
+	account, err := rlp.EncodeToBytes(&trie.Account{Nonce: 1, Balance: new(big.Int), Root: storageRoot, CodeHash: types.EmptyCodeHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountLeaf := leafProof(t, address.Bytes(), account)
+	stateRoot := crypto.Keccak256Hash(accountLeaf)
+
+	tests := []struct {
+		name        string
+		slot        string
+		stateRoot   common.Hash
+		storageHash common.Hash
+		want        string
+		verdict     Verdict
+	}{
+		{"verified slot", "2", stateRoot, storageRoot, "- uint256: 42", VerdictVerified},
+		{"hex slot", "0x2", stateRoot, storageRoot, "STORAGE PROOF VERIFICATION SUCCESSFUL", VerdictVerified},
+		{"empty slot", "3", stateRoot, storageRoot, "- Storage slot is empty", VerdictVerified},
+		{"storage hash not committed", "2", stateRoot, common.HexToHash("0x01"), "STORAGE ROOT MISMATCH", VerdictVerificationFailed},
+		{"account not under state root", "2", common.HexToHash("0x01"), storageRoot, "ACCOUNT PROOF VERIFICATION FAILED", VerdictVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := &types.Header{
+				Number:      big.NewInt(3),
+				Root:        tt.stateRoot,
+				UncleHash:   types.EmptyUncleHash,
+				TxHash:      types.EmptyTxsHash,
+				ReceiptHash: types.EmptyReceiptsHash,
+				Difficulty:  new(big.Int),
+			}
+			newTestNode(t, map[string]interface{}{
+				"eth_getBlockByNumber": blockResult(t, header, nil),
+				"eth_getProof": map[string]interface{}{
+					"address":      address,
+					"accountProof": []string{hexutil.Encode(accountLeaf)},
+					"balance":      "0x0",
+					"codeHash":     types.EmptyCodeHash,
+					"nonce":        "0x1",
+					"storageHash":  tt.storageHash,
+					"storageProof": []map[string]interface{}{
+						{"key": tt.slot, "value": "0x2a", "proof": []string{hexutil.Encode(storageLeaf)}},
+					},
+				},
+			})
+			accountAddress, blockHeight, storageSlotStrs = address.Hex(), 3, []string{tt.slot}
+
+			_, out, err := runReport(t, runStorageCommand)
+			if verdict := verdictOf(err); verdict != tt.verdict {
+				t.Fatalf("verdict %s (%v), want %s", verdict, err, tt.verdict)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Fatalf("output does not contain %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestExpandStorageSlots(t *testing.T) {
+	mapping, err := slot.Parse("mapping(4)[0xbeef]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted, err := slot.Parse(`mapping(2)["a..b"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		specs []string
+		exprs []string
+		keys  []common.Hash
+	}{
+		{
+			name:  "single slots",
+			specs: []string{"0", "mapping(4)[0xbeef]"},
+			exprs: []string{"0", "mapping(4)[0xbeef]"},
+			keys:  []common.Hash{{}, mapping.Key},
+		},
+		{
+			name:  "decimal range",
+			specs: []string{"2..4"},
+			exprs: []string{"2", "3", "4"},
+		},
+		{
+			name:  "hex range with spaces",
+			specs: []string{" 0x0a .. 0x0b "},
+			exprs: []string{"10", "11"},
+		},
+		{
+			name:  "single slot range",
+			specs: []string{"5..5"},
+			exprs: []string{"5"},
+		},
+		{
+			name:  "duplicates are dropped",
+			specs: []string{"1..3", "2", "0x3", "0"},
+			exprs: []string{"1", "2", "3", "0"},
+		},
+		{
+			name:  "quoted key containing dots",
+			specs: []string{`mapping(2)["a..b"]`},
+			exprs: []string{`mapping(2)["a..b"]`},
+			keys:  []common.Hash{quoted.Key},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			derivations, err := expandStorageSlots(tt.specs, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var exprs []string
+			for _, d := range derivations {
+				exprs = append(exprs, d.Expr)
+			}
+			if strings.Join(exprs, ",") != strings.Join(tt.exprs, ",") {
+				t.Fatalf("slots %v, want %v", exprs, tt.exprs)
+			}
+			for i, key := range tt.keys {
+				if derivations[i].Key != key {
+					t.Errorf("slot %d key %s, want %s", i, derivations[i].Key.Hex(), key.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestExpandStorageSlotsErrors(t *testing.T) {
+	tooMany := make([]string, maxStorageSlots+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		name  string
+		specs []string
+		want  string
+	}{
+		{"reversed range", []string{"5..2"}, "end is before start"},
+		{"range too large", []string{fmt.Sprintf("0..%d", maxStorageSlots)}, "more than"},
+		{"too many slots", tooMany, "too many storage slots"},
+		{"expression range", []string{"array(6)[0]..array(6)[2]"}, "plain decimal or 0x"},
+		{"open range", []string{"1.."}, "plain decimal or 0x"},
+		{"bad expression", []string{"mapping(1)"}, "invalid storage slot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := expandStorageSlots(tt.specs, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v, want one containing %q", err, tt.want)
+			}
+			if code := exitCode(err); code != ExitInvalidInput {
+				t.Fatalf("exit code %d, want %d", code, ExitInvalidInput)
+			}
+		})
+	}
+}
+
+func TestExpandStorageSlotsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slots.txt")
+	data := "# token storage\n0\n\n  1..2  # totals\nmapping(4)[0xbeef]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	derivations, err := expandStorageSlots([]string{"7"}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exprs []string
+	for _, d := range derivations {
+		exprs = append(exprs, d.Expr)
+	}
+	if want := "7,0,1,2,mapping(4)[0xbeef]"; strings.Join(exprs, ",") != want {
+		t.Fatalf("slots %v, want %s", exprs, want)
+	}
+
+	if _, err := expandStorageSlots(nil, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("expected an error for a missing slots file")
+	}
+}
@@ -2,71 +2,164 @@ package cli
 
 import (
 	"context"
-	"fmt"
-	"os"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	gethtrie "github.com/ethereum/go-ethereum/trie"
 	"github.com/inchori/gethtried/internal/geth"
+	"github.com/inchori/gethtried/internal/render"
+	"github.com/inchori/gethtried/internal/trie"
 	"github.com/spf13/cobra"
 )
 
+var (
+	txIndex   int
+	txHashStr string
+)
+
 var txCmd = &cobra.Command{
 	Use:   "tx",
 	Short: "Visualize the transaction trie for a specific block height",
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := runTxCommand(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+	Run:   runCommand("tx", runTxCommand),
 }
 
-func runTxCommand() error {
+func runTxCommand(report *Report) error {
 	if blockHeight < 0 {
-		return fmt.Errorf("block height must be non-negative, got: %d", blockHeight)
+		return invalidInputf("block height must be non-negative, got: %d", blockHeight)
 	}
 
 	client, err := geth.NewEthClient(rpcURL)
 	if err != nil {
-		return fmt.Errorf("failed to connect to RPC endpoint %s: %w", rpcURL, err)
+		return rpcFailuref("failed to connect to RPC endpoint %s: %w", rpcURL, err)
 	}
 
 	latestBlock, err := client.GetBlockByNumber(context.Background(), -1)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block (check RPC connection): %w", err)
+		return rpcFailuref("failed to get latest block (check RPC connection): %w", err)
 	}
 
 	if uint64(blockHeight) > latestBlock.NumberU64() {
-		return fmt.Errorf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
+		return invalidInputf("block height %d exceeds latest block %d", blockHeight, latestBlock.NumberU64())
 	}
 
 	block, err := client.GetBlockByNumber(context.Background(), blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get block %d: %w", blockHeight, err)
+		return rpcFailuref("failed to get block %d: %w", blockHeight, err)
 	}
 	expectedRoot := block.Header().TxHash
 	transactions := block.Transactions()
 
 	calculatedRoot := gethtypes.DeriveSha(transactions, gethtrie.NewStackTrie(nil))
+	txTrie := trie.DeriveTrie(transactions)
+	localRoot := txTrie.Hash()
 
-	fmt.Printf("Block Header TxRoot: %s\n", expectedRoot.Hex())
-	fmt.Printf("Calculated TxRoot:   %s\n", calculatedRoot.Hex())
-	if expectedRoot == calculatedRoot {
-		fmt.Println("Verification Successful!")
+	printf("Block Header TxRoot: %s\n", expectedRoot.Hex())
+	printf("Calculated TxRoot:   %s\n", calculatedRoot.Hex())
+	printf("Local Trie TxRoot:   %s\n", localRoot.Hex())
+	rootsMatch := expectedRoot == calculatedRoot && expectedRoot == localRoot
+	report.Block = render.NewBlockJSON(block.Header())
+	report.Roots = &Roots{
+		Header:     expectedRoot,
+		Calculated: calculatedRoot,
+		Local:      localRoot,
+		Match:      rootsMatch,
+	}
+	if rootsMatch {
+		printLine("Verification Successful!")
 	} else {
-		fmt.Println("Verification FAILED!")
+		printLine("Verification FAILED!")
 	}
 
-	fmt.Println("\n--- Transactions in Trie (Key: RLP(index)) ---")
+	if txIndex < 0 && txHashStr == "" {
+		printLine("\n--- Transactions in Trie (Key: RLP(index)) ---")
+		for i, tx := range transactions {
+			printf("  [Idx %d] TxHash: %s\n", i, tx.Hash().Hex())
+			report.Items = append(report.Items, Item{Index: i, TxHash: tx.Hash()})
+		}
+
+		printLine()
+		if textOutput() {
+			render.RenderTrie(txTrie.Root())
+		}
+		report.addTrieGraph("Transaction Trie", txTrie.Root())
+		if !rootsMatch {
+			return verificationFailedf("transaction root mismatch: header %s, DeriveSha %s, local trie %s", expectedRoot.Hex(), calculatedRoot.Hex(), localRoot.Hex())
+		}
+		return nil
+	}
+
+	txHashes := make([]common.Hash, len(transactions))
 	for i, tx := range transactions {
-		fmt.Printf("  [Idx %d] TxHash: %s\n", i, tx.Hash().Hex())
+		txHashes[i] = tx.Hash()
 	}
 
-	return nil
+	index, err := resolveTxIndex(txHashes, txIndex, txHashStr)
+	if err != nil {
+		return err
+	}
+
+	key := rlp.AppendUint64(nil, uint64(index))
+	proof := txTrie.Prove(key)
+
+	printf("\n--- Transaction Inclusion Proof ---\n")
+	printf("Index:       %d\n", index)
+	printf("TxHash:      %s\n", transactions[index].Hash().Hex())
+	printf("Key:         %s (RLP(index))\n", hexutil.Encode(key))
+	printf("Proof Nodes: %d\n", len(proof))
+
+	result := trie.VerifyProof(expectedRoot, key, proof)
+	report.Index = &index
+	report.Proof = render.NewProofJSON(result, proof)
+	report.addProofGraph("Transaction Proof", result)
+	if err := result.Err(); err != nil {
+		printf("PROOF VERIFICATION FAILED: %v\n", err)
+	} else if !result.Exists() {
+		printf("PROOF VERIFICATION FAILED: transaction is not included under header TxRoot\n")
+	} else {
+		printf("PROOF VERIFICATION SUCCESSFUL\n")
+		var verifiedTx gethtypes.Transaction
+		if err := verifiedTx.UnmarshalBinary(result.Value); err != nil {
+			return verificationFailedf("failed to decode verified transaction: %w", err)
+		}
+		report.Transaction = &verifiedTx
+	}
+
+	printf("\n--- Transaction Trie Path Visualization ---\n")
+	renderPath(result, proof, render.ValueTransaction)
+
+	return inclusionProofError(result, "transaction")
+}
+
+func resolveTxIndex(txHashes []common.Hash, index int, txHash string) (int, error) {
+	if index >= 0 && txHash != "" {
+		return 0, invalidInputf("--index and --tx-hash are mutually exclusive")
+	}
+
+	if txHash != "" {
+		hashBytes, err := hexutil.Decode(txHash)
+		if err != nil || len(hashBytes) != common.HashLength {
+			return 0, invalidInputf("invalid transaction hash: %s (expected 32-byte hex with 0x prefix)", txHash)
+		}
+		target := common.BytesToHash(hashBytes)
+		for i, hash := range txHashes {
+			if hash == target {
+				return i, nil
+			}
+		}
+		return 0, invalidInputf("transaction %s not found in block %d", txHash, blockHeight)
+	}
+
+	if index >= len(txHashes) {
+		return 0, invalidInputf("transaction index %d out of range (block %d has %d transactions)", index, blockHeight, len(txHashes))
+	}
+	return index, nil
 }
 
 func init() {
 	rootCmd.AddCommand(txCmd)
+	txCmd.Flags().IntVar(&txIndex, "index", -1, "Transaction index to prove")
+	txCmd.Flags().StringVar(&txHashStr, "tx-hash", "", "Transaction hash to prove (0x-prefixed)")
 	_ = txCmd.MarkFlagRequired("block-height")
 }
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/spf13/cobra"
+)
+
+func testTransactions(t *testing.T, n int) types.Transactions {
+	t.Helper()
+	key, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	to := common.HexToAddress("0x000000000000000000000000000000000000beef")
+	txs := make(types.Transactions, n)
+	for i := range txs {
+		tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: uint64(i), To: &to, Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1), Value: big.NewInt(int64(i))})
+		if txs[i], err = types.SignTx(tx, signer, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return txs
+}
+
+func TestTxIndexProof(t *testing.T) {
+	txs := testTransactions(t, 3)
+	header := &types.Header{
+		Number:      big.NewInt(5),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.DeriveSha(txs, gethtrie.NewStackTrie(nil)),
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  new(big.Int),
+	}
+	newTestNode(t, map[string]interface{}{"eth_getBlockByNumber": blockResult(t, header, txs)})
+
+	tests := []struct {
+		name   string
+		index  int
+		txHash string
+		want   string
+		err    string
+	}{
+		{name: "list", index: -1, want: "[Idx 2] TxHash: " + txs[2].Hash().Hex()},
+		{name: "by index", index: 1, want: "TxHash:      " + txs[1].Hash().Hex()},
+		{name: "by hash", index: -1, txHash: txs[2].Hash().Hex(), want: "Index:       2"},
+		{name: "index out of range", index: 3, err: "out of range"},
+		{name: "unknown hash", index: -1, txHash: common.HexToHash("0x01").Hex(), err: "not found"},
+		{name: "index and hash", index: 0, txHash: txs[0].Hash().Hex(), err: "mutually exclusive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockHeight, txIndex, txHashStr = 5, tt.index, tt.txHash
+			_, out, err := runReport(t, runTxCommand)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want one containing %q", err, tt.err)
+				}
+				if code := exitCode(err); code != ExitInvalidInput {
+					t.Fatalf("exit code %d, want %d", code, ExitInvalidInput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, "Verification Successful!") || !strings.Contains(out, tt.want) {
+				t.Fatalf("output does not contain %q:\n%s", tt.want, out)
+			}
+			if tt.index >= 0 || tt.txHash != "" {
+				if !strings.Contains(out, "PROOF VERIFICATION SUCCESSFUL") {
+					t.Fatalf("proof not verified:\n%s", out)
+				}
+			}
+		})
+	}
+
+	derived := header.TxHash
+	header.TxHash = common.HexToHash("0x01")
+	newTestNode(t, map[string]interface{}{"eth_getBlockByNumber": blockResult(t, header, txs)})
+	blockHeight, txIndex, txHashStr = 5, -1, ""
+	_, _, err := runReport(t, runTxCommand)
+	want := fmt.Sprintf("header %s, DeriveSha %s, local trie %s", header.TxHash.Hex(), derived.Hex(), derived.Hex())
+	if exitCode(err) != ExitVerificationFailed || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %v, want a verification failure containing %q", err, want)
+	}
+}
+
+func TestValidateIndex(t *testing.T) {
+	tests := []struct {
+		args []string
+		ok   bool
+	}{
+		{nil, true},
+		{[]string{"--index", "0"}, true},
+		{[]string{"--index", "-1"}, false},
+		{[]string{"--index=-5"}, false},
+	}
+	for _, tt := range tests {
+		cmd := &cobra.Command{}
+		var index int
+		cmd.Flags().IntVar(&index, "index", -1, "")
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		err := validateIndex(cmd)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateIndex(%v) = %v, want ok %v", tt.args, err, tt.ok)
+		}
+		if err != nil && exitCode(err) != ExitInvalidInput {
+			t.Errorf("validateIndex(%v) exit code %d, want %d", tt.args, exitCode(err), ExitInvalidInput)
+		}
+	}
+}
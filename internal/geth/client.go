@@ -2,17 +2,32 @@ package geth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+var ErrStateUnavailable = errors.New("state unavailable")
+
+// stateUnavailableMessages match errors from nodes that have the block but no
+// longer keep its state. "header not found" is deliberately absent: it means
+// the block itself does not exist.
+var stateUnavailableMessages = []string{
+	"missing trie node",
+	"historical state",
+	"state not available",
+	"state is not available",
+	"required historical state unavailable",
+	"pruned",
+}
+
 type Client struct {
 	ethClient *ethclient.Client
 }
@@ -34,6 +49,15 @@ func (e *Client) GetBlockByNumber(ctx context.Context, blockHeight int64) (*geth
 	return block, nil
 }
 
+func (e *Client) GetHeaderByNumber(ctx context.Context, blockHeight int64) (*gethtypes.Header, error) {
+	header, err := e.ethClient.HeaderByNumber(ctx, big.NewInt(blockHeight))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header by number: %v", err)
+	}
+
+	return header, nil
+}
+
 func (e *Client) GetTransactionReceipt(ctx context.Context, txHash common.Hash) (*gethtypes.Receipt, error) {
 	txReceipt, err := e.ethClient.TransactionReceipt(ctx, txHash)
 	if err != nil {
@@ -61,29 +85,37 @@ func (e *Client) GetAccountProof(ctx context.Context, address string, blockNumbe
 	gethClient := gethclient.New(e.ethClient.Client())
 	accountProof, err := gethClient.GetProof(ctx, accountAddress, nil, blockNumBig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get proof for account %s at block #%d: %v", address, blockNumber, err)
+		return nil, classifyProofError(fmt.Errorf("failed to get proof for account %s at block #%d: %v", address, blockNumber, err))
 	}
 
 	return accountProof, nil
 }
 
-func (e *Client) GetStorageProof(ctx context.Context, address string, slot int64, blockNumber int64) (*gethclient.AccountResult, error) {
+func (e *Client) GetStorageProof(ctx context.Context, address string, slots []common.Hash, blockNumber int64) (*gethclient.AccountResult, error) {
 	accountAddress := common.HexToAddress(address)
 	blockNumBig := big.NewInt(blockNumber)
 
 	gethClient := gethclient.New(e.ethClient.Client())
 
-	slotBigInt := big.NewInt(slot)
-	slotBytes := slotBigInt.Bytes()
-	paddedSlotBytes := common.LeftPadBytes(slotBytes, 32)
-	slotHex := hexutil.Encode(paddedSlotBytes)
-
-	keys := []string{slotHex}
+	keys := make([]string, len(slots))
+	for i, slot := range slots {
+		keys[i] = slot.Hex()
+	}
 
 	storageProof, err := gethClient.GetProof(ctx, accountAddress, keys, blockNumBig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get storage proof for account %s at block #%d: %v", address, blockNumber, err)
+		return nil, classifyProofError(fmt.Errorf("failed to get storage proof for account %s at block #%d: %v", address, blockNumber, err))
 	}
 
 	return storageProof, nil
 }
+
+func classifyProofError(err error) error {
+	message := strings.ToLower(err.Error())
+	for _, pattern := range stateUnavailableMessages {
+		if strings.Contains(message, pattern) {
+			return fmt.Errorf("%w: %v", ErrStateUnavailable, err)
+		}
+	}
+	return err
+}
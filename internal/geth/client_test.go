@@ -0,0 +1,31 @@
+package geth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyProofError(t *testing.T) {
+	tests := []struct {
+		message     string
+		unavailable bool
+	}{
+		{"missing trie node 0x1234 (path )", true},
+		{"historical state 0xabcd is not available", true},
+		{"state not available", true},
+		{"State Is Not Available for block 5", true},
+		{"required historical state unavailable (reexec=128)", true},
+		{"block pruned", true},
+		{"header not found", false},
+		{"connection refused", false},
+		{"unknown block", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			err := classifyProofError(errors.New(tt.message))
+			if got := errors.Is(err, ErrStateUnavailable); got != tt.unavailable {
+				t.Fatalf("state unavailable = %v, want %v", got, tt.unavailable)
+			}
+		})
+	}
+}
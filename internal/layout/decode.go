@@ -0,0 +1,184 @@
+package layout
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inchori/gethtried/internal/slot"
+)
+
+const (
+	maxArrayElements = 16
+	maxBytesSlots    = 128
+)
+
+type Value struct {
+	Label   string      `json:"label"`
+	Type    string      `json:"type"`
+	Slot    common.Hash `json:"slot"`
+	Offset  int         `json:"offset"`
+	Value   string      `json:"value,omitempty"`
+	Members []Value     `json:"members,omitempty"`
+}
+
+type ReadFunc func(key common.Hash) (common.Hash, error)
+
+func (l *Layout) Decode(loc *Location, read ReadFunc) (*Value, error) {
+	value, err := l.decode(loc.Expr, loc.Slot, loc.Offset, loc.Type, read)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func (l *Layout) decode(label string, at common.Hash, offset int, typeID string, read ReadFunc) (Value, error) {
+	t, err := l.Type(typeID)
+	if err != nil {
+		return Value{}, err
+	}
+	v := Value{Label: label, Type: t.Label, Slot: at, Offset: offset}
+
+	switch {
+	case t.Encoding == "mapping":
+		v.Value = "(index it with [key] to read an entry)"
+		return v, nil
+
+	case t.Encoding == "bytes":
+		v.Value, err = decodeBytes(t, at, read)
+		return v, err
+
+	case t.Encoding == "dynamic_array":
+		word, err := read(at)
+		if err != nil {
+			return v, err
+		}
+		length := word.Big()
+		v.Value = fmt.Sprintf("length %s", length)
+		first := slot.ArrayElement(at, new(big.Int), big.NewInt(1))
+		v.Members, err = l.decodeElements(label, t, first, length, read)
+		return v, err
+
+	case len(t.Members) > 0:
+		for _, m := range t.Members {
+			memberOffset, ok := new(big.Int).SetString(m.Slot, 10)
+			if !ok {
+				return v, fmt.Errorf("member %s has invalid slot %q", m.Label, m.Slot)
+			}
+			member, err := l.decode(label+"."+m.Label, slot.Add(at, memberOffset), m.Offset, m.Type, read)
+			if err != nil {
+				return v, err
+			}
+			v.Members = append(v.Members, member)
+		}
+		return v, nil
+
+	case t.Base != "":
+		length := staticLength(t.Label)
+		if length == nil {
+			return v, fmt.Errorf("cannot determine length of %s", t.Label)
+		}
+		v.Members, err = l.decodeElements(label, t, at, length, read)
+		return v, err
+	}
+
+	word, err := read(at)
+	if err != nil {
+		return v, err
+	}
+	size := t.Size()
+	if size <= 0 || offset+size > common.HashLength {
+		return v, fmt.Errorf("%s does not fit in a slot at offset %d", t.Label, offset)
+	}
+	v.Value = FormatValue(t.Label, word[common.HashLength-offset-size:common.HashLength-offset])
+	return v, nil
+}
+
+func (l *Layout) decodeElements(label string, t *Type, first common.Hash, length *big.Int, read ReadFunc) ([]Value, error) {
+	base, err := l.Type(t.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	count := maxArrayElements
+	if length.IsInt64() && length.Int64() < int64(count) {
+		count = int(length.Int64())
+	}
+
+	var elements []Value
+	for i := 0; i < count; i++ {
+		slotOffset, byteOffset := elementPosition(base.Size(), big.NewInt(int64(i)))
+		element, err := l.decode(fmt.Sprintf("%s[%d]", label, i), slot.Add(first, slotOffset), byteOffset, t.Base, read)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	if rest := new(big.Int).Sub(length, big.NewInt(int64(count))); rest.Sign() > 0 {
+		elements = append(elements, Value{Label: fmt.Sprintf("%s[%d..]", label, count), Type: base.Label, Value: fmt.Sprintf("(%s more elements not decoded)", rest)})
+	}
+	return elements, nil
+}
+
+// decodeBytes reads a string or bytes value: short values live in the slot
+// itself with length*2 in the lowest byte, long values store length*2+1 and
+// keep their data from keccak256(slot) on.
+func decodeBytes(t *Type, at common.Hash, read ReadFunc) (string, error) {
+	word, err := read(at)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	if word[31]&1 == 0 {
+		length := int(word[31]) / 2
+		if length > 31 {
+			return "", fmt.Errorf("invalid short %s encoding: length %d", t.Label, length)
+		}
+		data = word[:length]
+	} else {
+		length := new(big.Int).Rsh(word.Big(), 1)
+		slots := new(big.Int).Div(new(big.Int).Add(length, big.NewInt(31)), big.NewInt(32))
+		if slots.Cmp(big.NewInt(maxBytesSlots)) > 0 {
+			return "", fmt.Errorf("%s is %s bytes long, more than the %d slots this tool reads", t.Label, length, maxBytesSlots)
+		}
+		first := slot.ArrayElement(at, new(big.Int), big.NewInt(1))
+		for i := int64(0); i < slots.Int64(); i++ {
+			chunk, err := read(slot.Add(first, big.NewInt(i)))
+			if err != nil {
+				return "", err
+			}
+			data = append(data, chunk[:]...)
+		}
+		data = data[:length.Int64()]
+	}
+
+	if t.Label == "string" {
+		return strconv.Quote(string(data)), nil
+	}
+	return hexutil.Encode(data), nil
+}
+
+func FormatValue(label string, b []byte) string {
+	value := new(big.Int).SetBytes(b)
+	switch {
+	case label == "bool":
+		if value.Cmp(big.NewInt(1)) <= 0 {
+			return strconv.FormatBool(value.Sign() == 1)
+		}
+		return fmt.Sprintf("%s (invalid bool)", hexutil.Encode(b))
+	case strings.HasPrefix(label, "address"), strings.HasPrefix(label, "contract "):
+		return common.BytesToAddress(b).Hex()
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "enum "):
+		return value.String()
+	case strings.HasPrefix(label, "int"):
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+		return value.String()
+	}
+	return hexutil.Encode(b)
+}
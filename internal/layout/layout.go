@@ -0,0 +1,436 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inchori/gethtried/internal/slot"
+)
+
+type Variable struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+type Type struct {
+	Encoding      string     `json:"encoding"`
+	Label         string     `json:"label"`
+	NumberOfBytes string     `json:"numberOfBytes"`
+	Key           string     `json:"key,omitempty"`
+	Value         string     `json:"value,omitempty"`
+	Base          string     `json:"base,omitempty"`
+	Members       []Variable `json:"members,omitempty"`
+}
+
+type Layout struct {
+	Storage []Variable       `json:"storage"`
+	Types   map[string]*Type `json:"types"`
+
+	// Name is set for presets and qualifies their variables when Merge finds
+	// the same label in more than one layout.
+	Name string `json:"-"`
+}
+
+type Location struct {
+	Expr   string
+	Slot   common.Hash
+	Offset int
+	Type   string
+	Steps  []slot.Step
+}
+
+func Load(path string) (*Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Layout
+		StorageLayout *Layout `json:"storageLayout"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid storage layout JSON: %w", err)
+	}
+	layout := &file.Layout
+	if file.StorageLayout != nil {
+		layout = file.StorageLayout
+	}
+	if len(layout.Storage) == 0 {
+		return nil, fmt.Errorf("no storage variables found (expected a storageLayout object or an artifact containing one)")
+	}
+	return layout, nil
+}
+
+func (l *Layout) Type(id string) (*Type, error) {
+	t, ok := l.Types[id]
+	if !ok {
+		return nil, fmt.Errorf("type %s is not defined in the layout", id)
+	}
+	return t, nil
+}
+
+func (t *Type) Size() int {
+	size, _ := strconv.Atoi(t.NumberOfBytes)
+	return size
+}
+
+func (l *Layout) Resolve(expr string) (*Location, error) {
+	p := &varParser{input: expr}
+	name := p.identifier()
+	if name == "" {
+		return nil, fmt.Errorf("expression must start with a variable name")
+	}
+
+	variable := l.variable(name)
+	if variable == nil {
+		// Merged presets qualify clashing labels, e.g. erc721._name.
+		pos := p.pos
+		if p.consume(".") {
+			if qualified := name + "." + p.identifier(); l.variable(qualified) != nil {
+				name, variable = qualified, l.variable(qualified)
+			} else {
+				p.pos = pos
+			}
+		}
+	}
+	if variable == nil {
+		return nil, fmt.Errorf("unknown variable %q (declared: %s)", name, strings.Join(l.labels(), ", "))
+	}
+
+	start, ok := new(big.Int).SetString(variable.Slot, 10)
+	if !ok {
+		return nil, fmt.Errorf("variable %s has invalid slot %q", name, variable.Slot)
+	}
+	loc := &Location{
+		Expr:   name,
+		Slot:   common.BigToHash(start),
+		Offset: variable.Offset,
+		Type:   variable.Type,
+	}
+	declared := variable.Slot
+	if !start.IsUint64() {
+		declared = loc.Slot.Hex()
+	}
+	loc.Steps = append(loc.Steps, slot.Step{
+		Expr:    name,
+		Formula: fmt.Sprintf("declared at slot %s, offset %d", declared, variable.Offset),
+		Slot:    loc.Slot,
+	})
+
+	for !p.done() {
+		var err error
+		switch {
+		case p.consume("["):
+			err = l.index(loc, p)
+		case p.consume("."):
+			err = l.member(loc, p)
+		default:
+			err = fmt.Errorf("unexpected %q at offset %d", p.input[p.pos:], p.pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return loc, nil
+}
+
+func (l *Layout) index(loc *Location, p *varParser) error {
+	keyText, err := p.bracketed()
+	if err != nil {
+		return err
+	}
+	t, err := l.Type(loc.Type)
+	if err != nil {
+		return err
+	}
+	expr := fmt.Sprintf("%s[%s]", loc.Expr, keyText)
+
+	switch {
+	case t.Encoding == "mapping":
+		keyType, err := l.Type(t.Key)
+		if err != nil {
+			return err
+		}
+		key, err := EncodeKey(keyType, keyText)
+		if err != nil {
+			return fmt.Errorf("%s: %w", expr, err)
+		}
+		result := slot.Mapping(loc.Slot, key)
+		loc.Steps = append(loc.Steps, slot.Step{
+			Expr:    expr,
+			Formula: fmt.Sprintf("keccak256(%s . %s)", hexutil.Encode(key), loc.Slot.Hex()),
+			Slot:    result,
+		})
+		loc.Slot, loc.Offset, loc.Type = result, 0, t.Value
+
+	case t.Encoding == "dynamic_array" || t.Base != "":
+		index, ok := parseUint(keyText)
+		if !ok {
+			return fmt.Errorf("%s: array index must be a non-negative number", expr)
+		}
+		base, err := l.Type(t.Base)
+		if err != nil {
+			return err
+		}
+		if t.Encoding != "dynamic_array" {
+			if length := staticLength(t.Label); length != nil && index.Cmp(length) >= 0 {
+				return fmt.Errorf("%s: index out of bounds for %s", expr, t.Label)
+			}
+		}
+
+		first := loc.Slot
+		formula := loc.Slot.Hex()
+		if t.Encoding == "dynamic_array" {
+			first = slot.ArrayElement(loc.Slot, new(big.Int), big.NewInt(1))
+			formula = fmt.Sprintf("keccak256(%s)", loc.Slot.Hex())
+		}
+		slotOffset, byteOffset := elementPosition(base.Size(), index)
+		loc.Slot = slot.Add(first, slotOffset)
+		loc.Offset, loc.Type = byteOffset, t.Base
+		loc.Steps = append(loc.Steps, slot.Step{
+			Expr:    expr,
+			Formula: fmt.Sprintf("%s + %s, offset %d", formula, slotOffset, byteOffset),
+			Slot:    loc.Slot,
+		})
+
+	default:
+		return fmt.Errorf("%s: %s is neither a mapping nor an array", expr, t.Label)
+	}
+	loc.Expr = expr
+	return nil
+}
+
+func (l *Layout) member(loc *Location, p *varParser) error {
+	name := p.identifier()
+	if name == "" {
+		return fmt.Errorf("expected a member name at offset %d", p.pos)
+	}
+	t, err := l.Type(loc.Type)
+	if err != nil {
+		return err
+	}
+	if len(t.Members) == 0 {
+		return fmt.Errorf("%s: %s has no members", loc.Expr, t.Label)
+	}
+
+	for _, m := range t.Members {
+		if m.Label != name {
+			continue
+		}
+		offset, ok := new(big.Int).SetString(m.Slot, 10)
+		if !ok {
+			return fmt.Errorf("member %s has invalid slot %q", name, m.Slot)
+		}
+		base := loc.Slot
+		loc.Expr = loc.Expr + "." + name
+		loc.Slot, loc.Offset, loc.Type = slot.Add(base, offset), m.Offset, m.Type
+		loc.Steps = append(loc.Steps, slot.Step{
+			Expr:    loc.Expr,
+			Formula: fmt.Sprintf("%s + %s, offset %d", base.Hex(), m.Slot, m.Offset),
+			Slot:    loc.Slot,
+		})
+		return nil
+	}
+
+	var names []string
+	for _, m := range t.Members {
+		names = append(names, m.Label)
+	}
+	return fmt.Errorf("%s has no member %q (members: %s)", t.Label, name, strings.Join(names, ", "))
+}
+
+func (l *Layout) variable(label string) *Variable {
+	for i := range l.Storage {
+		if l.Storage[i].Label == label {
+			return &l.Storage[i]
+		}
+	}
+	return nil
+}
+
+func (l *Layout) labels() []string {
+	var labels []string
+	for _, v := range l.Storage {
+		labels = append(labels, v.Label)
+	}
+	return labels
+}
+
+// elementPosition follows solc packing: as many elements as fit share a slot,
+// and elements larger than a slot span whole slots.
+func elementPosition(size int, index *big.Int) (*big.Int, int) {
+	if size <= 0 {
+		size = 32
+	}
+	if size > 32 {
+		slots := big.NewInt(int64((size + 31) / 32))
+		return new(big.Int).Mul(index, slots), 0
+	}
+	perSlot := big.NewInt(int64(32 / size))
+	slotOffset, position := new(big.Int).DivMod(index, perSlot, new(big.Int))
+	return slotOffset, int(position.Int64()) * size
+}
+
+func staticLength(label string) *big.Int {
+	open := strings.LastIndexByte(label, '[')
+	if open < 0 || !strings.HasSuffix(label, "]") {
+		return nil
+	}
+	length, ok := new(big.Int).SetString(label[open+1:len(label)-1], 10)
+	if !ok {
+		return nil
+	}
+	return length
+}
+
+func EncodeKey(t *Type, text string) ([]byte, error) {
+	label := t.Label
+	switch {
+	case label == "string" || label == "bytes":
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			return []byte(unquoted), nil
+		}
+		if label == "bytes" {
+			if b, err := hexutil.Decode(text); err == nil {
+				return b, nil
+			}
+			return nil, fmt.Errorf("bytes key must be a quoted string or 0x hex")
+		}
+		return nil, fmt.Errorf("string key must be a quoted string")
+
+	case label == "bool":
+		switch text {
+		case "true":
+			return common.BigToHash(big.NewInt(1)).Bytes(), nil
+		case "false":
+			return common.Hash{}.Bytes(), nil
+		}
+		return nil, fmt.Errorf("bool key must be true or false")
+
+	case strings.HasPrefix(label, "address"), strings.HasPrefix(label, "contract "):
+		if !common.IsHexAddress(text) {
+			return nil, fmt.Errorf("%s key must be a 20-byte hex address", label)
+		}
+		return common.BytesToHash(common.HexToAddress(text).Bytes()).Bytes(), nil
+
+	case label == "bytes32" && strings.HasPrefix(text, "keccak256(") && strings.HasSuffix(text, ")"):
+		preimage, err := strconv.Unquote(text[len("keccak256(") : len(text)-1])
+		if err != nil {
+			return nil, fmt.Errorf("keccak256 key must hash a quoted string, e.g. keccak256(\"MINTER_ROLE\")")
+		}
+		return crypto.Keccak256([]byte(preimage)), nil
+
+	case strings.HasPrefix(label, "bytes"):
+		b, err := hexutil.Decode(text)
+		if err != nil || len(b) > t.Size() {
+			return nil, fmt.Errorf("%s key must be at most %d bytes of 0x hex", label, t.Size())
+		}
+		return common.RightPadBytes(b, common.HashLength), nil
+
+	case strings.HasPrefix(label, "int"):
+		value, ok := parseInt(text)
+		if !ok {
+			return nil, fmt.Errorf("%s key must be a number", label)
+		}
+		if value.Sign() < 0 {
+			value.Add(value, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return common.BigToHash(value).Bytes(), nil
+	}
+
+	value, ok := parseUint(text)
+	if !ok {
+		return nil, fmt.Errorf("%s key must be a non-negative number", label)
+	}
+	return common.BigToHash(value).Bytes(), nil
+}
+
+func parseUint(text string) (*big.Int, bool) {
+	value, ok := parseInt(text)
+	if !ok || value.Sign() < 0 || value.BitLen() > 256 {
+		return nil, false
+	}
+	return value, true
+}
+
+func parseInt(text string) (*big.Int, bool) {
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	var (
+		value *big.Int
+		ok    bool
+	)
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		value, ok = new(big.Int).SetString(text[2:], 16)
+	} else {
+		value, ok = new(big.Int).SetString(text, 10)
+	}
+	if !ok {
+		return nil, false
+	}
+	if negative {
+		value.Neg(value)
+	}
+	return value, true
+}
+
+type varParser struct {
+	input string
+	pos   int
+}
+
+func (p *varParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *varParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *varParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *varParser) bracketed() (string, error) {
+	start := p.pos
+	quoted := false
+	for ; p.pos < len(p.input); p.pos++ {
+		switch c := p.input[p.pos]; {
+		case c == '\\' && quoted:
+			p.pos++
+		case c == '"':
+			quoted = !quoted
+		case c == ']' && !quoted:
+			text := strings.TrimSpace(p.input[start:p.pos])
+			p.pos++
+			if text == "" {
+				return "", fmt.Errorf("empty index at offset %d", start)
+			}
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("missing \"]\" for index starting at offset %d", start-1)
+}
@@ -0,0 +1,237 @@
+package layout
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testLayout = &Layout{
+	Storage: []Variable{
+		{Label: "owner", Offset: 0, Slot: "0", Type: "t_address"},
+		{Label: "paused", Offset: 20, Slot: "0", Type: "t_bool"},
+		{Label: "balances", Offset: 0, Slot: "1", Type: "t_mapping(t_address,t_uint256)"},
+		{Label: "small", Offset: 0, Slot: "2", Type: "t_array(t_uint64)dyn_storage"},
+		{Label: "name", Offset: 0, Slot: "3", Type: "t_string_storage"},
+		{Label: "fixed", Offset: 0, Slot: "5", Type: "t_array(t_uint128)3_storage"},
+		{Label: "point", Offset: 0, Slot: "7", Type: "t_struct(Point)_storage"},
+	},
+	Types: map[string]*Type{
+		"t_address":                      {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+		"t_bool":                         {Encoding: "inplace", Label: "bool", NumberOfBytes: "1"},
+		"t_uint64":                       {Encoding: "inplace", Label: "uint64", NumberOfBytes: "8"},
+		"t_uint128":                      {Encoding: "inplace", Label: "uint128", NumberOfBytes: "16"},
+		"t_uint256":                      {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+		"t_string_storage":               {Encoding: "bytes", Label: "string", NumberOfBytes: "32"},
+		"t_mapping(t_address,t_uint256)": {Encoding: "mapping", Label: "mapping(address => uint256)", NumberOfBytes: "32", Key: "t_address", Value: "t_uint256"},
+		"t_array(t_uint64)dyn_storage":   {Encoding: "dynamic_array", Label: "uint64[]", NumberOfBytes: "32", Base: "t_uint64"},
+		"t_array(t_uint128)3_storage":    {Encoding: "inplace", Label: "uint128[3]", NumberOfBytes: "64", Base: "t_uint128"},
+		"t_struct(Point)_storage": {Encoding: "inplace", Label: "struct Point", NumberOfBytes: "64", Members: []Variable{
+			{Label: "x", Offset: 0, Slot: "0", Type: "t_uint256"},
+			{Label: "y", Offset: 0, Slot: "1", Type: "t_uint128"},
+			{Label: "z", Offset: 16, Slot: "1", Type: "t_uint128"},
+		}},
+	},
+}
+
+func slotOf(n int64) common.Hash {
+	return common.BigToHash(big.NewInt(n))
+}
+
+func TestResolve(t *testing.T) {
+	holder := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	smallData := new(big.Int).SetBytes(crypto.Keccak256(slotOf(2).Bytes()))
+
+	tests := []struct {
+		expr   string
+		slot   common.Hash
+		offset int
+		typ    string
+	}{
+		{"owner", slotOf(0), 0, "t_address"},
+		{"paused", slotOf(0), 20, "t_bool"},
+		{"balances[" + holder.Hex() + "]", crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), 32), slotOf(1).Bytes()), 0, "t_uint256"},
+		{"small[0]", common.BigToHash(smallData), 0, "t_uint64"},
+		{"small[5]", common.BigToHash(new(big.Int).Add(smallData, big.NewInt(1))), 8, "t_uint64"},
+		{"fixed[0]", slotOf(5), 0, "t_uint128"},
+		{"fixed[2]", slotOf(6), 0, "t_uint128"},
+		{"fixed[1]", slotOf(5), 16, "t_uint128"},
+		{"point.x", slotOf(7), 0, "t_uint256"},
+		{"point.z", slotOf(8), 16, "t_uint128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			loc, err := testLayout.Resolve(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loc.Slot != tt.slot || loc.Offset != tt.offset || loc.Type != tt.typ {
+				t.Fatalf("got slot %s offset %d type %s, want slot %s offset %d type %s",
+					loc.Slot.Hex(), loc.Offset, loc.Type, tt.slot.Hex(), tt.offset, tt.typ)
+			}
+			if last := loc.Steps[len(loc.Steps)-1]; last.Slot != loc.Slot {
+				t.Fatalf("last step slot %s is not the resolved slot", last.Slot.Hex())
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"missing", "unknown variable"},
+		{"owner[1]", "neither a mapping nor an array"},
+		{"balances[1]", "20-byte hex address"},
+		{"fixed[3]", "out of bounds"},
+		{"small[-1]", "non-negative"},
+		{"point.w", "no member"},
+		{"balances[", "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := testLayout.Resolve(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"bare layout", `{"storage": [{"label": "owner", "slot": "0", "type": "t_address"}], "types": {}}`, ""},
+		{"compiler output", `{"storageLayout": {"storage": [{"label": "owner", "slot": "0", "type": "t_address"}], "types": {}}}`, ""},
+		{"artifact", `{"abi": [], "storageLayout": {"storage": [{"label": "owner", "slot": "0", "type": "t_address"}]}}`, ""},
+		{"no variables", `{"abi": []}`, "no storage variables found"},
+		{"invalid JSON", `{"storage": [`, "invalid storage layout JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "layout.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			l, err := Load(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(l.Storage) != 1 || l.Storage[0].Label != "owner" {
+				t.Fatalf("storage %+v, want the owner variable", l.Storage)
+			}
+		})
+	}
+}
+
+func TestElementPosition(t *testing.T) {
+	tests := []struct {
+		size   int
+		index  int64
+		slot   int64
+		offset int
+	}{
+		{1, 0, 0, 0},
+		{1, 31, 0, 31},
+		{1, 32, 1, 0},
+		{8, 5, 1, 8},
+		{12, 3, 1, 12},
+		{16, 1, 0, 16},
+		{32, 3, 3, 0},
+		{33, 1, 2, 0},
+		{64, 3, 6, 0},
+	}
+	for _, tt := range tests {
+		slotOffset, byteOffset := elementPosition(tt.size, big.NewInt(tt.index))
+		if slotOffset.Int64() != tt.slot || byteOffset != tt.offset {
+			t.Errorf("elementPosition(%d, %d) = %s, %d, want %d, %d", tt.size, tt.index, slotOffset, byteOffset, tt.slot, tt.offset)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	long := strings.Repeat("abcdefghij", 4)
+
+	storage := map[common.Hash]common.Hash{}
+	word := common.BytesToHash(owner.Bytes())
+	word[common.HashLength-21] = 1
+	storage[slotOf(0)] = word
+	storage[slotOf(3)] = slotOf(int64(len(long)*2 + 1))
+	data := common.BigToHash(new(big.Int).SetBytes(crypto.Keccak256(slotOf(3).Bytes())))
+	storage[data] = common.BytesToHash([]byte(long[:32]))
+	storage[common.BigToHash(new(big.Int).Add(data.Big(), big.NewInt(1)))] = common.BytesToHash(common.RightPadBytes([]byte(long[32:]), 32))
+	storage[slotOf(5)] = common.BigToHash(new(big.Int).Add(new(big.Int).Lsh(big.NewInt(2), 128), big.NewInt(1)))
+	storage[slotOf(6)] = slotOf(3)
+
+	read := func(key common.Hash) (common.Hash, error) { return storage[key], nil }
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"owner", owner.Hex()},
+		{"paused", "true"},
+		{"name", `"` + long + `"`},
+		{"fixed[0]", "1"},
+		{"fixed[1]", "2"},
+		{"fixed[2]", "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			loc, err := testLayout.Resolve(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := testLayout.Decode(loc, read)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Value != tt.want {
+				t.Fatalf("value %s, want %s", v.Value, tt.want)
+			}
+		})
+	}
+
+	short := common.BytesToHash(common.RightPadBytes([]byte("hello"), 32))
+	short[31] = 10
+	storage[slotOf(3)] = short
+	loc, err := testLayout.Resolve("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := testLayout.Decode(loc, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Value != `"hello"` {
+		t.Fatalf("short string decoded to %s", v.Value)
+	}
+
+	loc, err = testLayout.Resolve("fixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = testLayout.Decode(loc, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Members) != 3 || v.Members[1].Value != "2" {
+		t.Fatalf("fixed array decoded to %+v", v.Members)
+	}
+}
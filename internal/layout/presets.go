@@ -0,0 +1,176 @@
+package layout
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/inchori/gethtried/internal/slot"
+)
+
+// presets describe the ERC-7201 namespaced storage of OpenZeppelin
+// Contracts Upgradeable v5.
+var presets = map[string]func() *Layout{
+	"erc20": func() *Layout {
+		return namespaced("openzeppelin.storage.ERC20",
+			Variable{Label: "_balances", Slot: "0", Type: "t_mapping(t_address,t_uint256)"},
+			Variable{Label: "_allowances", Slot: "1", Type: "t_mapping(t_address,t_mapping(t_address,t_uint256))"},
+			Variable{Label: "_totalSupply", Slot: "2", Type: "t_uint256"},
+			Variable{Label: "_name", Slot: "3", Type: "t_string_storage"},
+			Variable{Label: "_symbol", Slot: "4", Type: "t_string_storage"},
+		)
+	},
+	"erc721": func() *Layout {
+		return namespaced("openzeppelin.storage.ERC721",
+			Variable{Label: "_name", Slot: "0", Type: "t_string_storage"},
+			Variable{Label: "_symbol", Slot: "1", Type: "t_string_storage"},
+			Variable{Label: "_owners", Slot: "2", Type: "t_mapping(t_uint256,t_address)"},
+			Variable{Label: "_balances", Slot: "3", Type: "t_mapping(t_address,t_uint256)"},
+			Variable{Label: "_tokenApprovals", Slot: "4", Type: "t_mapping(t_uint256,t_address)"},
+			Variable{Label: "_operatorApprovals", Slot: "5", Type: "t_mapping(t_address,t_mapping(t_address,t_bool))"},
+		)
+	},
+	"ownable": func() *Layout {
+		return namespaced("openzeppelin.storage.Ownable",
+			Variable{Label: "_owner", Slot: "0", Type: "t_address"},
+		)
+	},
+	"accesscontrol": func() *Layout {
+		return namespaced("openzeppelin.storage.AccessControl",
+			Variable{Label: "_roles", Slot: "0", Type: "t_mapping(t_bytes32,t_struct(RoleData)_storage)"},
+		)
+	},
+	"pausable": func() *Layout {
+		return namespaced("openzeppelin.storage.Pausable",
+			Variable{Label: "_paused", Slot: "0", Type: "t_bool"},
+		)
+	},
+	"initializable": func() *Layout {
+		return namespaced("openzeppelin.storage.Initializable",
+			Variable{Label: "_initialized", Slot: "0", Type: "t_uint64"},
+			Variable{Label: "_initializing", Offset: 8, Slot: "0", Type: "t_bool"},
+		)
+	},
+}
+
+var presetTypes = map[string]*Type{
+	"t_address":        {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+	"t_bool":           {Encoding: "inplace", Label: "bool", NumberOfBytes: "1"},
+	"t_bytes32":        {Encoding: "inplace", Label: "bytes32", NumberOfBytes: "32"},
+	"t_uint64":         {Encoding: "inplace", Label: "uint64", NumberOfBytes: "8"},
+	"t_uint256":        {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+	"t_string_storage": {Encoding: "bytes", Label: "string", NumberOfBytes: "32"},
+	"t_mapping(t_address,t_bool)": {
+		Encoding: "mapping", Label: "mapping(address => bool)", NumberOfBytes: "32", Key: "t_address", Value: "t_bool",
+	},
+	"t_mapping(t_address,t_uint256)": {
+		Encoding: "mapping", Label: "mapping(address => uint256)", NumberOfBytes: "32", Key: "t_address", Value: "t_uint256",
+	},
+	"t_mapping(t_uint256,t_address)": {
+		Encoding: "mapping", Label: "mapping(uint256 => address)", NumberOfBytes: "32", Key: "t_uint256", Value: "t_address",
+	},
+	"t_mapping(t_address,t_mapping(t_address,t_uint256))": {
+		Encoding: "mapping", Label: "mapping(address => mapping(address => uint256))", NumberOfBytes: "32", Key: "t_address", Value: "t_mapping(t_address,t_uint256)",
+	},
+	"t_mapping(t_address,t_mapping(t_address,t_bool))": {
+		Encoding: "mapping", Label: "mapping(address => mapping(address => bool))", NumberOfBytes: "32", Key: "t_address", Value: "t_mapping(t_address,t_bool)",
+	},
+	"t_mapping(t_bytes32,t_struct(RoleData)_storage)": {
+		Encoding: "mapping", Label: "mapping(bytes32 => struct AccessControlUpgradeable.RoleData)", NumberOfBytes: "32", Key: "t_bytes32", Value: "t_struct(RoleData)_storage",
+	},
+	"t_struct(RoleData)_storage": {
+		Encoding: "inplace", Label: "struct AccessControlUpgradeable.RoleData", NumberOfBytes: "64",
+		Members: []Variable{
+			{Label: "hasRole", Slot: "0", Type: "t_mapping(t_address,t_bool)"},
+			{Label: "adminRole", Slot: "1", Type: "t_bytes32"},
+		},
+	},
+}
+
+func namespaced(namespace string, variables ...Variable) *Layout {
+	base := slot.ERC7201(namespace).Big()
+	l := &Layout{Types: make(map[string]*Type)}
+	for _, v := range variables {
+		offset, _ := new(big.Int).SetString(v.Slot, 10)
+		v.Slot = new(big.Int).Add(base, offset).String()
+		l.Storage = append(l.Storage, v)
+		l.addType(v.Type)
+	}
+	return l
+}
+
+func (l *Layout) addType(id string) {
+	t := presetTypes[id]
+	if t == nil || l.Types[id] != nil {
+		return
+	}
+	l.Types[id] = t
+	for _, child := range []string{t.Key, t.Value, t.Base} {
+		if child != "" {
+			l.addType(child)
+		}
+	}
+	for _, m := range t.Members {
+		l.addType(m.Type)
+	}
+}
+
+func PresetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns a built-in layout. Presets only cover the ERC-7201
+// namespaced storage of OpenZeppelin Contracts Upgradeable v5; the
+// non-upgradeable contracts and earlier versions use sequential slots and
+// need a --layout file instead.
+func Preset(name string) (*Layout, error) {
+	preset, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (expected one of: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	l := preset()
+	l.Name = strings.ToLower(name)
+	return l, nil
+}
+
+// Merge combines layouts into one. A label declared by more than one layout
+// is qualified with the preset name (erc20._name, erc721._name); clashing
+// labels of an unnamed layout, such as a --layout file, are left as they are.
+// Layouts may share a type ID only if they define it identically.
+func Merge(layouts ...*Layout) (*Layout, error) {
+	merged := &Layout{Types: make(map[string]*Type)}
+	declared := make(map[string]int)
+	for _, l := range layouts {
+		for _, v := range l.Storage {
+			declared[v.Label]++
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range layouts {
+		for _, v := range l.Storage {
+			if declared[v.Label] > 1 && l.Name != "" {
+				v.Label = l.Name + "." + v.Label
+			}
+			if seen[v.Label] {
+				return nil, fmt.Errorf("variable %s is declared by more than one layout", v.Label)
+			}
+			seen[v.Label] = true
+			merged.Storage = append(merged.Storage, v)
+		}
+		for id, t := range l.Types {
+			if existing, ok := merged.Types[id]; ok && !reflect.DeepEqual(existing, t) {
+				return nil, fmt.Errorf("type %s is defined differently by more than one layout", id)
+			}
+			merged.Types[id] = t
+		}
+	}
+	return merged, nil
+}
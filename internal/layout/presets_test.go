@@ -0,0 +1,152 @@
+package layout
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/inchori/gethtried/internal/slot"
+)
+
+func TestPreset(t *testing.T) {
+	l, err := Preset("ERC20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name != "erc20" {
+		t.Fatalf("preset name %q, want erc20", l.Name)
+	}
+	loc, err := l.Resolve("_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := slot.Add(slot.ERC7201("openzeppelin.storage.ERC20"), big.NewInt(3))
+	if loc.Slot != want || loc.Type != "t_string_storage" {
+		t.Fatalf("_name at %s (%s), want %s", loc.Slot.Hex(), loc.Type, want.Hex())
+	}
+
+	for _, name := range PresetNames() {
+		l, err := Preset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range l.Storage {
+			if _, err := l.Type(v.Type); err != nil {
+				t.Errorf("%s.%s: %v", name, v.Label, err)
+			}
+		}
+	}
+
+	if _, err := Preset("erc1155"); err == nil || !strings.Contains(err.Error(), "unknown preset") {
+		t.Fatalf("error %v, want unknown preset", err)
+	}
+}
+
+func TestMergeQualifiesClashingLabels(t *testing.T) {
+	erc20, _ := Preset("erc20")
+	erc721, _ := Preset("erc721")
+	ownable, _ := Preset("ownable")
+	merged, err := Merge(erc20, erc721, ownable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	erc721Base := slot.ERC7201("openzeppelin.storage.ERC721")
+	tests := []struct {
+		expr string
+		slot common.Hash
+	}{
+		{"erc20._name", slot.Add(slot.ERC7201("openzeppelin.storage.ERC20"), big.NewInt(3))},
+		{"erc721._name", erc721Base},
+		{"erc721._symbol", slot.Add(erc721Base, big.NewInt(1))},
+		{"_owners[1]", slot.Mapping(slot.Add(erc721Base, big.NewInt(2)), common.BigToHash(big.NewInt(1)).Bytes())},
+		{"_totalSupply", slot.Add(slot.ERC7201("openzeppelin.storage.ERC20"), big.NewInt(2))},
+		{"_owner", slot.ERC7201("openzeppelin.storage.Ownable")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			loc, err := merged.Resolve(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loc.Slot != tt.slot {
+				t.Fatalf("slot %s, want %s", loc.Slot.Hex(), tt.slot.Hex())
+			}
+		})
+	}
+
+	if _, err := merged.Resolve("_name"); err == nil || !strings.Contains(err.Error(), "erc721._name") {
+		t.Fatalf("error %v, want the qualified labels listed", err)
+	}
+	if _, err := merged.Resolve("erc1155._name"); err == nil {
+		t.Fatal("expected an error for an unknown qualifier")
+	}
+}
+
+func TestMergeRejectsDuplicates(t *testing.T) {
+	a, _ := Preset("erc20")
+	b, _ := Preset("erc20")
+	if _, err := Merge(a, b); err == nil {
+		t.Fatal("expected merging a preset with itself to fail")
+	}
+
+	file := &Layout{
+		Storage: []Variable{{Label: "_name", Slot: "0", Type: "t_string_storage"}},
+		Types:   map[string]*Type{"t_string_storage": presetTypes["t_string_storage"]},
+	}
+	c, _ := Preset("erc20")
+	merged, err := Merge(file, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := merged.Resolve("_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Slot != (common.Hash{}) {
+		t.Fatalf("unqualified _name should be the file variable, got slot %s", loc.Slot.Hex())
+	}
+	if _, err := merged.Resolve("erc20._name"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeTypes(t *testing.T) {
+	address := &Type{Encoding: "inplace", Label: "address", NumberOfBytes: "20"}
+	tests := []struct {
+		name  string
+		types []*Type
+		err   string
+	}{
+		{"identical", []*Type{address, {Encoding: "inplace", Label: "address", NumberOfBytes: "20"}}, ""},
+		{"different size", []*Type{address, {Encoding: "inplace", Label: "address", NumberOfBytes: "32"}}, "t_address is defined differently"},
+		{"different encoding", []*Type{address, {Encoding: "bytes", Label: "address", NumberOfBytes: "20"}}, "t_address is defined differently"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Layout{
+				Storage: []Variable{{Label: "owner", Slot: "0", Type: "t_address"}},
+				Types:   map[string]*Type{"t_address": tt.types[0]},
+			}
+			b := &Layout{
+				Name:    "other",
+				Storage: []Variable{{Label: "admin", Slot: "1", Type: "t_address"}},
+				Types:   map[string]*Type{"t_address": tt.types[1]},
+			}
+			merged, err := Merge(a, b)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(merged.Storage) != 2 || merged.Types["t_address"].Size() != 20 {
+				t.Fatalf("merged layout %+v", merged)
+			}
+		})
+	}
+}
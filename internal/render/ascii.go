@@ -2,204 +2,250 @@ package render
 
 import (
 	"fmt"
-	"log"
-	"math/big"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/params"
 	"github.com/inchori/gethtried/internal/trie"
 )
 
-func RenderProofPath(pathNodes []trie.RenderNode, value interface{}) {
-	fmt.Println("--- ASCII Trie Path Visualization ---")
-
-	indent := "  "
-	for i, data := range pathNodes {
-		prefix := "├──"
-		if i == len(pathNodes)-1 {
-			prefix = "└──"
-		}
-
-		fmt.Printf("%s%s [Node %d] KEY: %s\n", indent, prefix, i, hexutil.Encode(data.Key))
+func RenderLogicalPath(result *trie.VerificationResult, kind ValueKind, fullBranches bool) {
+	fmt.Println("--- Logical Trie Path Visualization ---")
+	fmt.Printf("Target Path: %s\n", result.Path)
+	printKeyAlignment(result)
+	fmt.Println()
+	if fullBranches {
+		defer printBranchOccupancy(result)
+	}
 
-		detailsIndent := indent + "│   "
-		fmt.Printf("%s     Type: %s\n", detailsIndent, data.Node.Type())
+	indent := ""
+	for i, step := range result.Steps {
+		fmt.Printf("%s├── KEY: %s\n", indent, stepReference(step))
+		fmt.Printf("%s│   Type: %s\n", indent, step.Node.Type())
 
-		switch n := data.Node.(type) {
-		case *trie.LeafNode:
-			switch val := value.(type) {
-			case *trie.Account:
-				weiFloat := new(big.Float).SetInt(val.Balance)
-				ethConstantFloat := new(big.Float).SetInt64(params.Ether)
-				ethValue := new(big.Float).Quo(weiFloat, ethConstantFloat)
-
-				fmt.Printf("%s   - Nonce:       %d\n", detailsIndent, val.Nonce)
-				fmt.Printf("%s   - Balance:     %s ETH\n", detailsIndent, ethValue.Text('f', 6))
-				fmt.Printf("%s   - StorageRoot: %s\n", detailsIndent, val.Root.Hex())
-				fmt.Printf("%s   - CodeHash:    %s\n", detailsIndent, val.CodeHash.Hex())
-			case []byte:
-				fmt.Printf("%s   - Value (32 bytes): %s\n", detailsIndent, hexutil.Encode(val))
-			default:
-				fmt.Printf("%s   -  Raw Value: %v\n", detailsIndent, n.Value)
+		switch n := step.Node.(type) {
+		case *trie.BranchNode:
+			fmt.Printf("%s│   - Has Value: %t\n", indent, len(n.Value) > 0)
+			if step.ChildIndex >= 0 && !step.ChildRef.Empty() {
+				fmt.Printf("%s│   -> Branching: Following path nibble '%s' (index %d, %s)\n",
+					indent, step.Consumed, step.ChildIndex, referenceKind(step.ChildRef))
+			} else if step.ChildIndex >= 0 {
+				fmt.Printf("%s│   -> Branching: Slot for path nibble '%s' (index %d) is empty\n",
+					indent, step.Consumed, step.ChildIndex)
+			}
+			if fullBranches {
+				printBranchSlots(n, step, indent+"│   ")
 			}
 		case *trie.ExtensionNode:
-			fmt.Printf("%s   - Next Hash: %x\n", detailsIndent, n.NextNode)
-		case *trie.BranchNode:
-			fmt.Printf("%s   - Has Value: %t\n", detailsIndent, len(n.Value) > 0)
+			sharedNibbles, _ := trie.DecodeHP(n.SharedPath)
+			fmt.Printf("%s│   - Shared Path: '%s'\n", indent, sharedNibbles)
+			if !step.ChildRef.Empty() {
+				fmt.Printf("%s│   -> Following Extension Node (%s)...\n", indent, referenceKind(step.ChildRef))
+			}
+		case *trie.LeafNode:
+			pathEnd, _ := trie.DecodeHP(n.PathEnd)
+			fmt.Printf("%s│   - Final Path: '%s'\n", indent, pathEnd)
 		}
-	}
-}
 
-func RenderLogicalPath(
-	startRootKey common.Hash,
-	targetPathNibbles string,
-	proofMap map[string]trie.RenderNodeData,
-	finalValue interface{},
-) {
-	fmt.Println("--- Logical Trie Path Visualization ---")
-	fmt.Printf("Target Path: %s\n", targetPathNibbles)
+		if !result.Verified && i == result.FailedStep {
+			fmt.Printf("%s│   └── ERROR at step %d: %s\n", indent, result.FailedStep, result.Reason)
+			return
+		}
+		if i < len(result.Steps)-1 {
+			indent += "│   "
+		}
+	}
 
-	walkRecursive(startRootKey.Hex(), targetPathNibbles, proofMap, finalValue, "")
-}
+	if !result.Verified {
+		fmt.Printf("%s└── ERROR at step %d: %s\n", indent, result.FailedStep, result.Reason)
+		return
+	}
 
-func walkRecursive(
-	currentNodeKey string,
-	remainingPath string,
-	proofMap map[string]trie.RenderNodeData,
-	finalValue interface{},
-	indent string,
-) {
-	data, ok := proofMap[currentNodeKey]
-	if !ok {
-		fmt.Printf("%s└── ERROR: Missing node in proof path! Hash: %s\n", indent, currentNodeKey)
+	if result.Exclusion != nil {
+		fmt.Printf("%s└── NON-INCLUSION VERIFIED (%s)\n", indent, result.Exclusion.Kind)
+		fmt.Printf("%s    - Reason: %s\n", indent, result.Exclusion.String())
+		fmt.Printf("%s    - Supporting Nodes: %d\n", indent, len(result.Steps))
 		return
 	}
 
-	fmt.Printf("%s├── KEY: %s\n", indent, currentNodeKey)
-	fmt.Printf("%s│   Type: %s\n", indent, data.Node.Type())
+	if n := len(result.Steps); n > 0 {
+		if _, ok := result.Steps[n-1].Node.(*trie.BranchNode); ok {
+			fmt.Printf("%s└── Branch value reached. Final Value:\n", indent)
+			printFinalValue(kind, result.Value, indent+"    ")
+			return
+		}
+	}
+	fmt.Printf("%s└── Leaf Reached. Final Value:\n", indent)
+	printFinalValue(kind, result.Value, indent+"    ")
+}
 
-	switch n := data.Node.(type) {
+func stepReference(step trie.VerificationStep) string {
+	if step.Inline {
+		return inlineReference(step.Raw)
+	}
+	return step.Hash.Hex()
+}
 
-	case *trie.BranchNode:
-		fmt.Printf("%s│   - Has Value: %t\n", indent, len(n.Value) > 0)
+func inlineReference(raw []byte) string {
+	return fmt.Sprintf("inline (embedded in parent, %d bytes)", len(raw))
+}
 
-		if len(remainingPath) == 0 {
-			if len(n.Value) > 0 {
-				fmt.Printf("%s└── Branch value reached. Final Value:\n", indent)
-				printFinalValue(finalValue, indent+"    ")
-				return
+func printKeyAlignment(result *trie.VerificationResult) {
+	const labelWidth = 10
+	width := len(result.Path)
+
+	fmt.Println("\n--- Key Alignment ---")
+	ruler := []byte(strings.Repeat(" ", width+4))
+	for i := 0; i < width; i += 8 {
+		copy(ruler[i:], fmt.Sprintf("%d", i))
+	}
+	fmt.Printf("%-*s%s\n", labelWidth, "nibble", strings.TrimRight(string(ruler), " "))
+	fmt.Printf("%-*s%s\n", labelWidth, "key", result.Path)
+
+	depth := 0
+	for i, step := range result.Steps {
+		segment := step.Consumed
+		note := ""
+		if e := result.Exclusion; e != nil && e.Step == i {
+			switch e.Kind {
+			case trie.ExclusionEmptyBranchSlot:
+				note = " (empty slot)"
+			case trie.ExclusionEmptyBranchValue:
+				note = " (no value)"
+			case trie.ExclusionExtensionDivergence, trie.ExclusionLeafKeyMismatch:
+				segment = e.NodePath
+				note = fmt.Sprintf(" (diverges at nibble %d)", step.Depth+e.DivergenceOffset())
 			}
-			fmt.Printf("%s│   └── ERROR: Path ended at Branch without value\n", indent)
-			return
 		}
 
-		nextNibbleChar := remainingPath[0]
-		nextNibbleIndex := hexNibbleToIndex(nextNibbleChar)
-		if nextNibbleIndex == -1 {
-			fmt.Printf("%s│   └── ERROR: Invalid path nibble '%c'\n", indent, nextNibbleChar)
-			return
+		line := strings.Repeat(" ", step.Depth) + segment
+		if len(line) < width {
+			line += strings.Repeat(" ", width-len(line))
 		}
+		depth = step.Depth + len(step.Consumed)
+		fmt.Printf("%-*s%s  %-9s depth %d -> %d%s\n", labelWidth, fmt.Sprintf("step %d", i), line, step.Node.Type(), step.Depth, depth, note)
 
-		fmt.Printf("%s│   -> Branching: Following path nibble '%c' (index %d)\n", indent, nextNibbleChar, nextNibbleIndex)
+		if e := result.Exclusion; e != nil && e.Step == i && (e.Kind == trie.ExclusionExtensionDivergence || e.Kind == trie.ExclusionLeafKeyMismatch) {
+			fmt.Printf("%-*s%s^\n", labelWidth, "", strings.Repeat(" ", step.Depth+e.DivergenceOffset()))
+		}
+	}
+	fmt.Printf("Resulting depth: %d of %d nibbles\n", depth, width)
+}
 
-		childRef := n.Children[nextNibbleIndex]
-		if len(childRef) == 0 {
-			fmt.Printf("%s│   └── ERROR: Path led to an empty slot in Branch node.\n", indent)
-			return
+func printBranchSlots(n *trie.BranchNode, step trie.VerificationStep, indent string) {
+	fmt.Printf("%s- Occupancy: %d/16 slots at depth %d\n", indent, branchOccupancy(n), step.Depth)
+	for i, child := range n.Children {
+		marker := "  "
+		if i == step.ChildIndex {
+			marker = "->"
+		}
+		var slot string
+		switch {
+		case child.Empty():
+			slot = "empty"
+		case child.Inline():
+			slot = fmt.Sprintf("inline %s node, %d bytes", child.Node.Type(), len(child.Raw))
+		default:
+			slot = hexutil.Encode(child.Hash)
 		}
+		fmt.Printf("%s  %s [%x] %s\n", indent, marker, i, slot)
+	}
+}
 
-		var childKeyHex string
-		if len(childRef) == 32 {
-			childKeyHex = hexutil.Encode(childRef)
-		} else {
-			h := crypto.Keccak256(childRef)
-			childKeyHex = hexutil.Encode(h)
-			parsedChild, err := trie.ParseNode(childRef)
-			if err != nil {
-				fmt.Printf("%s│   └── ERROR: Failed to parse inline child node: %v\n", indent, err)
-				return
-			}
-			proofMap[common.BytesToHash(h).Hex()] = trie.RenderNodeData{Key: common.BytesToHash(h), Node: parsedChild}
+func printBranchOccupancy(result *trie.VerificationResult) {
+	fmt.Println("\n--- Branch Occupancy ---")
+	branches, total := 0, 0
+	for i, step := range result.Steps {
+		n, ok := step.Node.(*trie.BranchNode)
+		if !ok {
+			continue
 		}
+		occupied := branchOccupancy(n)
+		branches++
+		total += occupied
+		fmt.Printf("  Step %d, depth %2d: %2d/16 %s%s\n", i, step.Depth, occupied,
+			strings.Repeat("#", occupied), strings.Repeat(".", 16-occupied))
+	}
+	if branches == 0 {
+		fmt.Println("  No branch nodes on this path")
+		return
+	}
+	fmt.Printf("  Average: %.1f/16 over %d branch nodes\n", float64(total)/float64(branches), branches)
+}
 
-		walkRecursive(childKeyHex, remainingPath[1:], proofMap, finalValue, indent+"│   ")
+func branchOccupancy(n *trie.BranchNode) int {
+	occupied := 0
+	for _, child := range n.Children {
+		if !child.Empty() {
+			occupied++
+		}
+	}
+	return occupied
+}
 
-	case *trie.ExtensionNode:
-		sharedNibbles, _ := trie.DecodeHP(n.SharedPath)
-		fmt.Printf("%s│   - Shared Path: '%s'\n", indent, sharedNibbles)
+func referenceKind(ref trie.Ref) string {
+	if ref.Inline() {
+		return fmt.Sprintf("inline %s node, %d bytes", ref.Node.Type(), len(ref.Raw))
+	}
+	return "hash reference"
+}
 
-		if !strings.HasPrefix(remainingPath, sharedNibbles) {
-			fmt.Printf("%s│   └── ERROR: Path mismatch. Expected prefix '%s' but got '%s'\n", indent, sharedNibbles, remainingPath)
-			return
+func RenderTrie(root trie.Node) {
+	fmt.Println("--- Trie Structure ---")
+	if root == nil {
+		fmt.Println("└── (empty trie)")
+		return
+	}
+	walkTrie(root, "", root.Hash().Hex(), "", true)
+}
+
+func walkTrie(n trie.Node, label string, reference string, indent string, last bool) {
+	prefix, childIndent := "├── ", indent+"│   "
+	if last {
+		prefix, childIndent = "└── ", indent+"    "
+	}
+
+	switch cur := n.(type) {
+	case *trie.BranchNode:
+		valueNote := ""
+		if len(cur.Value) > 0 {
+			valueNote = fmt.Sprintf(", value %d bytes", len(cur.Value))
 		}
+		fmt.Printf("%s%s%sBranch %s%s\n", indent, prefix, label, reference, valueNote)
 
-		fmt.Printf("%s│   -> Following Extension Node...\n", indent)
-		nextRef := n.NextNode
-		nextRemainingPath := remainingPath[len(sharedNibbles):]
-
-		var nextKeyHex string
-		if len(nextRef) == 32 {
-			nextKeyHex = hexutil.Encode(nextRef)
-		} else {
-			h := crypto.Keccak256(nextRef)
-			nextKeyHex = hexutil.Encode(h)
-			parsedNext, err := trie.ParseNode(nextRef)
-			if err != nil {
-				fmt.Printf("%s│   └── ERROR: Failed to parse inline next node: %v\n", indent, err)
-				return
+		var occupied []int
+		for i, child := range cur.Children {
+			if !child.Empty() {
+				occupied = append(occupied, i)
 			}
-			proofMap[common.BytesToHash(h).Hex()] = trie.RenderNodeData{Key: common.BytesToHash(h), Node: parsedNext}
+		}
+		for j, i := range occupied {
+			child := cur.Children[i]
+			walkTrie(child.Node, fmt.Sprintf("[%x] ", i), trieReference(child), childIndent, j == len(occupied)-1)
 		}
 
-		walkRecursive(nextKeyHex, nextRemainingPath, proofMap, finalValue, indent+"│   ")
+	case *trie.ExtensionNode:
+		sharedNibbles, _ := trie.DecodeHP(cur.SharedPath)
+		fmt.Printf("%s%s%sExtension '%s' %s\n", indent, prefix, label, sharedNibbles, reference)
+		walkTrie(cur.NextNode.Node, "", trieReference(cur.NextNode), childIndent, true)
 
 	case *trie.LeafNode:
-		pathEnd, _ := trie.DecodeHP(n.PathEnd)
-		fmt.Printf("%s│   - Final Path: '%s'\n", indent, pathEnd)
+		pathEnd, _ := trie.DecodeHP(cur.PathEnd)
+		fmt.Printf("%s%s%sLeaf '%s' %s, value %d bytes\n", indent, prefix, label, pathEnd, reference, len(cur.Value))
 
-		if remainingPath != pathEnd {
-			fmt.Printf("%s│   └── ERROR: Path mismatch. Expected final path '%s' but remaining path is '%s'\n", indent, pathEnd, remainingPath)
-			return
-		}
-
-		fmt.Printf("%s└── Leaf Reached. Final Value:\n", indent)
-		printFinalValue(finalValue, indent+"    ")
+	case nil:
+		fmt.Printf("%s%s%sUnresolved %s\n", indent, prefix, label, reference)
 	}
 }
 
-func printFinalValue(finalValue interface{}, indent string) {
-	switch val := finalValue.(type) {
-	case *trie.Account:
-		weiFloat := new(big.Float).SetInt(val.Balance)
-		ethConstantFloat := new(big.Float).SetInt64(params.Ether)
-		ethValue := new(big.Float).Quo(weiFloat, ethConstantFloat)
-
-		fmt.Printf("%s- Nonce:       %d\n", indent, val.Nonce)
-		fmt.Printf("%s- Balance:     %s ETH\n", indent, ethValue.Text('f', 6))
-		fmt.Printf("%s- StorageRoot: %s\n", indent, val.Root.Hex())
-		fmt.Printf("%s- CodeHash:    %s\n", indent, val.CodeHash.Hex())
-
-	case []byte:
-		fmt.Printf("%s- Value: %s\n", indent, hexutil.Encode(val))
-
-	default:
-		fmt.Printf("%s- Unknown Value Type\n", indent)
+func trieReference(ref trie.Ref) string {
+	if ref.Inline() {
+		return inlineReference(ref.Raw)
 	}
+	return hexutil.Encode(ref.Hash)
 }
 
-func hexNibbleToIndex(nibbleChar byte) int {
-	if nibbleChar >= '0' && nibbleChar <= '9' {
-		return int(nibbleChar - '0')
-	}
-	if nibbleChar >= 'a' && nibbleChar <= 'f' {
-		return int(nibbleChar-'a') + 10
-	}
-	if nibbleChar >= 'A' && nibbleChar <= 'F' {
-		return int(nibbleChar-'A') + 10
+func printFinalValue(kind ValueKind, value []byte, indent string) {
+	for _, line := range ValueLines(kind, value, indent) {
+		fmt.Println(line)
 	}
-	log.Fatalf("Invalid hex nibble character: %c", nibbleChar)
-	return -1
 }
@@ -0,0 +1,187 @@
+package render
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func encode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	raw, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// inlineProof returns a proof for key 0x12 whose leaf is embedded in the root
+// branch.
+func inlineProof(t *testing.T) *trie.VerificationResult {
+	t.Helper()
+	children := make([]interface{}, 17)
+	for i := range children {
+		children[i] = []byte{}
+	}
+	children[1] = rlp.RawValue(encode(t, [][]byte{{0x32}, {0x07}}))
+	children[3] = crypto.Keccak256(encode(t, [][]byte{{0x34}, make([]byte, 40)}))
+	branch := encode(t, children)
+
+	result := trie.VerifyProof(crypto.Keccak256Hash(branch), []byte{0x12}, [][]byte{branch})
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRenderLogicalPathInline(t *testing.T) {
+	result := inlineProof(t)
+	out := captureOutput(t, func() { RenderLogicalPath(result, ValueStorage, false) })
+
+	for _, want := range []string{
+		"KEY: " + result.Steps[0].Hash.Hex(),
+		"index 1, inline Leaf node, 3 bytes",
+		"KEY: inline (embedded in parent, 3 bytes)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, crypto.Keccak256Hash(result.Steps[1].Raw).Hex()) {
+		t.Errorf("inline step printed with a hash:\n%s", out)
+	}
+}
+
+func TestRenderLogicalPathFullBranches(t *testing.T) {
+	tests := []struct {
+		name         string
+		fullBranches bool
+		want         []string
+		absent       []string
+	}{
+		{
+			name:         "full branches",
+			fullBranches: true,
+			want: []string{
+				"- Occupancy: 2/16 slots at depth 0",
+				"   [0] empty",
+				"-> [1] inline Leaf node, 3 bytes",
+				"   [3] 0x",
+				"--- Branch Occupancy ---",
+				"Step 0, depth  0:  2/16 ##..............",
+				"Average: 2.0/16 over 1 branch nodes",
+			},
+		},
+		{
+			name:   "summary only",
+			absent: []string{"Occupancy", "[0] empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := inlineProof(t)
+			out := captureOutput(t, func() { RenderLogicalPath(result, ValueStorage, tt.fullBranches) })
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(out, absent) {
+					t.Errorf("output contains %q:\n%s", absent, out)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintKeyAlignment(t *testing.T) {
+	branch := inlineProof(t).Steps[0].Raw
+	root := crypto.Keccak256Hash(branch)
+
+	tests := []struct {
+		name string
+		key  []byte
+		want []string
+	}{
+		{
+			name: "inclusion",
+			key:  []byte{0x12},
+			want: []string{
+				"key       12",
+				"step 0    1   Branch    depth 0 -> 1",
+				"step 1     2  Leaf      depth 1 -> 2",
+				"Resulting depth: 2 of 2 nibbles",
+			},
+		},
+		{
+			name: "empty slot",
+			key:  []byte{0x22},
+			want: []string{"step 0    2   Branch    depth 0 -> 1 (empty slot)"},
+		},
+		{
+			name: "leaf key mismatch",
+			key:  []byte{0x15},
+			want: []string{
+				"step 1     2  Leaf      depth 1 -> 1 (diverges at nibble 1)",
+				"Resulting depth: 1 of 2 nibbles",
+				"           ^",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := trie.VerifyProof(root, tt.key, [][]byte{branch})
+			if !result.Verified {
+				t.Fatalf("verification failed: %s", result.Reason)
+			}
+			out := captureOutput(t, func() { printKeyAlignment(result) })
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTrieInline(t *testing.T) {
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x01}, []byte{0x01})
+	tr.Put([]byte{0x12}, make([]byte, 40))
+	out := captureOutput(t, func() { RenderTrie(tr.Root()) })
+
+	for _, want := range []string{
+		"Branch " + tr.Hash().Hex(),
+		"[0] Leaf '1' inline (embedded in parent, 3 bytes), value 1 bytes",
+		"[1] Leaf '2' 0x",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
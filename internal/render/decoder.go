@@ -0,0 +1,81 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type ValueKind string
+
+const (
+	ValueAccount     ValueKind = "account"
+	ValueStorage     ValueKind = "storage"
+	ValueTransaction ValueKind = "transaction"
+	ValueReceipt     ValueKind = "receipt"
+)
+
+type Field struct {
+	Name   string
+	Value  string
+	Fields []Field
+}
+
+type ValueDecoder interface {
+	DecodeValue(raw []byte) ([]Field, error)
+}
+
+type ValueDecoderFunc func(raw []byte) ([]Field, error)
+
+func (f ValueDecoderFunc) DecodeValue(raw []byte) ([]Field, error) {
+	return f(raw)
+}
+
+var (
+	valueDecodersMu sync.RWMutex
+	valueDecoders   = make(map[ValueKind]ValueDecoder)
+)
+
+func RegisterValueDecoder(kind ValueKind, decoder ValueDecoder) {
+	valueDecodersMu.Lock()
+	defer valueDecodersMu.Unlock()
+	valueDecoders[kind] = decoder
+}
+
+func DecodeValue(kind ValueKind, raw []byte) ([]Field, error) {
+	valueDecodersMu.RLock()
+	decoder, ok := valueDecoders[kind]
+	valueDecodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no value decoder registered for %q", kind)
+	}
+	return decoder.DecodeValue(raw)
+}
+
+func FormatFields(fields []Field, indent string) []string {
+	width := 0
+	for _, f := range fields {
+		if len(f.Name) > width {
+			width = len(f.Name)
+		}
+	}
+
+	var lines []string
+	for _, f := range fields {
+		line := fmt.Sprintf("%s- %-*s %s", indent, width+1, f.Name+":", f.Value)
+		lines = append(lines, strings.TrimRight(line, " "))
+		lines = append(lines, FormatFields(f.Fields, indent+"  ")...)
+	}
+	return lines
+}
+
+func ValueLines(kind ValueKind, raw []byte, indent string) []string {
+	fields, err := DecodeValue(kind, raw)
+	if err != nil {
+		return FormatFields([]Field{
+			{Name: "Raw Value", Value: fmt.Sprintf("0x%x", raw)},
+			{Name: "Decode Error", Value: err.Error()},
+		}, indent)
+	}
+	return FormatFields(fields, indent)
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestValueLines(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tx, err := types.NewTx(&types.LegacyTx{Nonce: 3, To: &to, Value: big.NewInt(5), Gas: 21000, GasPrice: big.NewInt(7)}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := (&types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{{Address: to, Topics: []common.Hash{{0x01}}, Data: []byte{0xff}}},
+	}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := encode(t, &trie.Account{
+		Nonce:    1,
+		Balance:  new(big.Int).Mul(big.NewInt(3), big.NewInt(params.Ether/2)),
+		Root:     types.EmptyRootHash,
+		CodeHash: types.EmptyCodeHash,
+	})
+
+	tests := []struct {
+		kind ValueKind
+		raw  []byte
+		want []string
+	}{
+		{ValueAccount, account, []string{"- Nonce:       1", "- Balance:     1.500000 ETH", "- StorageRoot: " + types.EmptyRootHash.Hex()}},
+		{ValueStorage, []byte{0x2a}, []string{"- RLP:     0x2a", "- uint256: 42", "- int256:  42", "- bool:    n/a (not 0 or 1)"}},
+		{ValueStorage, append([]byte{0xa0}, bytes.Repeat([]byte{0xff}, 32)...), []string{"- int256:  -1", "- address: 0xFFfFfFffFFfffFFfFFfFFFFFffFFFffffFfFFFfF"}},
+		{ValueStorage, []byte{0x01}, []string{"- bool:    true"}},
+		{ValueStorage, []byte{0xc0}, []string{"- Decode Error: storage value is not an RLP string"}},
+		{ValueTransaction, tx, []string{"- Nonce:    3", "- To:       " + to.Hex(), "- Value:    5 wei", "- GasPrice: 7 wei"}},
+		{ValueReceipt, receipt, []string{"- Type:              2", "- Status:            1", "- Logs:              1", "  - Log 0: " + to.Hex(), "    - Topic 0: 0x01", "    - Data:    0xff"}},
+		{ValueTransaction, []byte{0x01, 0x02}, []string{"- Raw Value:    0x0102", "- Decode Error: failed to decode transaction"}},
+		{"unknown", []byte{0x01}, []string{`- Decode Error: no value decoder registered for "unknown"`}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			out := strings.Join(ValueLines(tt.kind, tt.raw, ""), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("lines do not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterValueDecoder(t *testing.T) {
+	const kind ValueKind = "test-pair"
+	RegisterValueDecoder(kind, ValueDecoderFunc(func(raw []byte) ([]Field, error) {
+		if len(raw) != 2 {
+			return nil, errors.New("want 2 bytes")
+		}
+		return []Field{{Name: "Pair", Value: "ok", Fields: []Field{{Name: "A", Value: "1"}, {Name: "Bb", Value: "2"}}}}, nil
+	}))
+
+	got := strings.Join(ValueLines(kind, []byte{1, 2}, "  "), "\n")
+	want := "  - Pair: ok\n    - A:  1\n    - Bb: 2"
+	if got != want {
+		t.Fatalf("lines\n%s\nwant\n%s", got, want)
+	}
+	if _, err := DecodeValue(kind, []byte{1}); err == nil || err.Error() != "want 2 bytes" {
+		t.Fatalf("error %v, want the decoder's error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func init() {
+	RegisterValueDecoder(ValueAccount, ValueDecoderFunc(decodeAccountValue))
+	RegisterValueDecoder(ValueStorage, ValueDecoderFunc(decodeStorageValue))
+	RegisterValueDecoder(ValueTransaction, ValueDecoderFunc(decodeTransactionValue))
+	RegisterValueDecoder(ValueReceipt, ValueDecoderFunc(decodeReceiptValue))
+}
+
+func decodeAccountValue(raw []byte) ([]Field, error) {
+	var account trie.Account
+	if err := rlp.DecodeBytes(raw, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode account: %w", err)
+	}
+
+	weiFloat := new(big.Float).SetInt(account.Balance)
+	ethConstantFloat := new(big.Float).SetInt64(params.Ether)
+	ethValue := new(big.Float).Quo(weiFloat, ethConstantFloat)
+
+	return []Field{
+		{Name: "Nonce", Value: fmt.Sprintf("%d", account.Nonce)},
+		{Name: "Balance", Value: ethValue.Text('f', 6) + " ETH"},
+		{Name: "StorageRoot", Value: account.Root.Hex()},
+		{Name: "CodeHash", Value: account.CodeHash.Hex()},
+	}, nil
+}
+
+func decodeStorageValue(raw []byte) ([]Field, error) {
+	word, err := trie.DecodeStorageValue(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	unsigned := new(big.Int).SetBytes(word[:])
+	signed := new(big.Int).Set(unsigned)
+	if word[0]&0x80 != 0 {
+		signed.Sub(signed, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+
+	boolean := "n/a (not 0 or 1)"
+	if unsigned.Cmp(big.NewInt(1)) <= 0 {
+		boolean = fmt.Sprintf("%t", unsigned.Sign() == 1)
+	}
+
+	return []Field{
+		{Name: "RLP", Value: hexutil.Encode(raw)},
+		{Name: "Value", Value: word.Hex()},
+		{Name: "uint256", Value: unsigned.String()},
+		{Name: "int256", Value: signed.String()},
+		{Name: "address", Value: common.BytesToAddress(word[12:]).Hex()},
+		{Name: "bool", Value: boolean},
+	}, nil
+}
+
+func decodeTransactionValue(raw []byte) ([]Field, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+
+	to := "(contract creation)"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	return []Field{
+		{Name: "TxHash", Value: tx.Hash().Hex()},
+		{Name: "Type", Value: fmt.Sprintf("%d", tx.Type())},
+		{Name: "Nonce", Value: fmt.Sprintf("%d", tx.Nonce())},
+		{Name: "To", Value: to},
+		{Name: "Value", Value: tx.Value().String() + " wei"},
+		{Name: "Gas", Value: fmt.Sprintf("%d", tx.Gas())},
+		{Name: "GasPrice", Value: tx.GasPrice().String() + " wei"},
+		{Name: "Data", Value: fmt.Sprintf("%d bytes", len(tx.Data()))},
+	}, nil
+}
+
+func decodeReceiptValue(raw []byte) ([]Field, error) {
+	var receipt types.Receipt
+	if err := receipt.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode receipt: %w", err)
+	}
+
+	fields := []Field{{Name: "Type", Value: fmt.Sprintf("%d", receipt.Type)}}
+	if len(receipt.PostState) > 0 {
+		fields = append(fields, Field{Name: "PostState", Value: hexutil.Encode(receipt.PostState)})
+	} else {
+		fields = append(fields, Field{Name: "Status", Value: fmt.Sprintf("%d", receipt.Status)})
+	}
+	fields = append(fields,
+		Field{Name: "CumulativeGasUsed", Value: fmt.Sprintf("%d", receipt.CumulativeGasUsed)},
+		Field{Name: "Bloom", Value: hexutil.Encode(receipt.Bloom[:])},
+	)
+
+	logs := Field{Name: "Logs", Value: fmt.Sprintf("%d", len(receipt.Logs))}
+	for i, l := range receipt.Logs {
+		log := Field{Name: fmt.Sprintf("Log %d", i), Value: l.Address.Hex()}
+		for j, topic := range l.Topics {
+			log.Fields = append(log.Fields, Field{Name: fmt.Sprintf("Topic %d", j), Value: topic.Hex()})
+		}
+		log.Fields = append(log.Fields, Field{Name: "Data", Value: hexutil.Encode(l.Data)})
+		logs.Fields = append(logs.Fields, log)
+	}
+	return append(fields, logs), nil
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+type Graph struct {
+	Title  string
+	Result *trie.VerificationResult
+	Root   trie.Node
+}
+
+var nodeColors = map[string]string{
+	"Branch":    "#dae8fc",
+	"Extension": "#d5e8d4",
+	"Leaf":      "#fff2cc",
+}
+
+const (
+	followedSlotColor = "#ffd966"
+	emptySlotColor    = "#eeeeee"
+)
+
+func WriteDOT(w io.Writer, graphs []Graph) error {
+	var b bytes.Buffer
+	b.WriteString("digraph gethtried {\n")
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=plaintext, fontname=\"monospace\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"monospace\", fontsize=9];\n")
+
+	for i, g := range graphs {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=\"%s\";\n", dotEscape(g.Title))
+		if g.Result != nil {
+			writeDOTPath(&b, fmt.Sprintf("g%d_", i), g.Result)
+		} else {
+			writeDOTTrie(&b, fmt.Sprintf("g%d_", i), g.Root)
+		}
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeDOTPath(b *bytes.Buffer, prefix string, result *trie.VerificationResult) {
+	for i, step := range result.Steps {
+		id := fmt.Sprintf("%sn%d", prefix, i)
+		fmt.Fprintf(b, "    %s [label=<%s>];\n", id, dotNodeLabel(step.Node, stepReference(step), step.ChildIndex))
+		if i > 0 {
+			prev := result.Steps[i-1]
+			writeDOTEdge(b, fmt.Sprintf("%sn%d", prefix, i-1), dotPort(prev.Node, prev.ChildIndex), id, prev.ChildRef)
+		}
+	}
+
+	var note, color string
+	switch {
+	case !result.Verified:
+		note = fmt.Sprintf("VERIFICATION FAILED at step %d: %s", result.FailedStep, result.Reason)
+		color = "#f8cecc"
+	case result.Exclusion != nil:
+		note = fmt.Sprintf("NON-INCLUSION VERIFIED (%s): %s", result.Exclusion.Kind, result.Exclusion.String())
+		color = "#e1d5e7"
+	default:
+		return
+	}
+
+	noteID := prefix + "result"
+	fmt.Fprintf(b, "    %s [shape=note, style=filled, fillcolor=\"%s\", label=\"%s\"];\n", noteID, color, dotEscape(note))
+	if n := len(result.Steps); n > 0 {
+		last := result.Steps[n-1]
+		if !result.Verified && result.FailedStep == n && !last.ChildRef.Empty() {
+			writeDOTEdge(b, fmt.Sprintf("%sn%d", prefix, n-1), dotPort(last.Node, last.ChildIndex), noteID, last.ChildRef)
+		} else {
+			fmt.Fprintf(b, "    %sn%d -> %s [style=dotted, arrowhead=none];\n", prefix, n-1, noteID)
+		}
+	}
+}
+
+func writeDOTTrie(b *bytes.Buffer, prefix string, root trie.Node) {
+	if root == nil {
+		fmt.Fprintf(b, "    %sempty [shape=note, label=\"(empty trie)\"];\n", prefix)
+		return
+	}
+
+	counter := 0
+	var walk func(n trie.Node, reference string) string
+	walk = func(n trie.Node, reference string) string {
+		id := fmt.Sprintf("%sn%d", prefix, counter)
+		counter++
+		fmt.Fprintf(b, "    %s [label=<%s>];\n", id, dotNodeLabel(n, reference, -1))
+
+		switch cur := n.(type) {
+		case *trie.BranchNode:
+			for i, child := range cur.Children {
+				if child.Empty() || child.Node == nil {
+					continue
+				}
+				childID := walk(child.Node, trieReference(child))
+				writeDOTEdge(b, id, dotPort(n, i), childID, child)
+			}
+		case *trie.ExtensionNode:
+			if cur.NextNode.Node != nil {
+				childID := walk(cur.NextNode.Node, trieReference(cur.NextNode))
+				writeDOTEdge(b, id, dotPort(n, -1), childID, cur.NextNode)
+			}
+		}
+		return id
+	}
+	walk(root, root.Hash().Hex())
+}
+
+func writeDOTEdge(b *bytes.Buffer, from, port, to string, ref trie.Ref) {
+	if ref.Inline() {
+		fmt.Fprintf(b, "    %s%s -> %s [style=dashed, label=\"inline\"];\n", from, port, to)
+		return
+	}
+	fmt.Fprintf(b, "    %s%s -> %s [label=\"hash\"];\n", from, port, to)
+}
+
+func dotPort(n trie.Node, childIndex int) string {
+	switch n.(type) {
+	case *trie.BranchNode:
+		if childIndex >= 0 {
+			return fmt.Sprintf(":c%x:s", childIndex)
+		}
+	case *trie.ExtensionNode:
+		return ":next:s"
+	}
+	return ""
+}
+
+func dotNodeLabel(n trie.Node, reference string, followed int) string {
+	var b strings.Builder
+	title := n.Type()
+
+	columns := 1
+	if _, ok := n.(*trie.BranchNode); ok {
+		columns = 16
+	}
+
+	b.WriteString(`<table border="0" cellborder="1" cellspacing="0">`)
+	fmt.Fprintf(&b, `<tr><td colspan="%d" bgcolor="%s"><b>%s</b></td></tr>`, columns, nodeColors[n.Type()], title)
+	fmt.Fprintf(&b, `<tr><td colspan="%d">%s</td></tr>`, columns, html.EscapeString(reference))
+
+	switch cur := n.(type) {
+	case *trie.BranchNode:
+		b.WriteString("<tr>")
+		for i, child := range cur.Children {
+			color := "white"
+			switch {
+			case i == followed:
+				color = followedSlotColor
+			case child.Empty():
+				color = emptySlotColor
+			}
+			fmt.Fprintf(&b, `<td port="c%x" bgcolor="%s">%x</td>`, i, color, i)
+		}
+		b.WriteString("</tr>")
+		if len(cur.Value) > 0 {
+			fmt.Fprintf(&b, `<tr><td colspan="16">value: %s</td></tr>`, dotValue(cur.Value))
+		}
+	case *trie.ExtensionNode:
+		sharedNibbles, _ := trie.DecodeHP(cur.SharedPath)
+		fmt.Fprintf(&b, `<tr><td>path: '%s'</td></tr>`, sharedNibbles)
+		b.WriteString(`<tr><td port="next">next</td></tr>`)
+	case *trie.LeafNode:
+		pathEnd, _ := trie.DecodeHP(cur.PathEnd)
+		fmt.Fprintf(&b, `<tr><td>path: '%s'</td></tr>`, pathEnd)
+		fmt.Fprintf(&b, `<tr><td>value: %s</td></tr>`, dotValue(cur.Value))
+	}
+
+	b.WriteString("</table>")
+	return b.String()
+}
+
+// dotEscape escapes s for a double-quoted DOT string, in which only '"' and
+// '\' are special. Newlines become the \n line break.
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotValue(value []byte) string {
+	if len(value) > 32 {
+		return fmt.Sprintf("%d bytes", len(value))
+	}
+	return html.EscapeString(hexutil.Encode(value))
+}
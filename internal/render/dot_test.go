@@ -0,0 +1,97 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestWriteDOT(t *testing.T) {
+	included := inlineProof(t)
+	branch := included.Steps[0].Raw
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x01}, []byte{0x01})
+	tr.Put([]byte{0x12}, make([]byte, 40))
+
+	tests := []struct {
+		name  string
+		graph Graph
+		want  []string
+	}{
+		{
+			name:  "inline step",
+			graph: Graph{Title: "Transaction proof", Result: included},
+			want: []string{
+				`label="Transaction proof";`,
+				included.Root.Hex(),
+				"inline (embedded in parent, 3 bytes)",
+				`g0_n0:c1:s -> g0_n1 [style=dashed, label="inline"];`,
+			},
+		},
+		{
+			name:  "exclusion note",
+			graph: Graph{Title: "Absent", Result: trie.VerifyProof(included.Root, []byte{0x22}, [][]byte{branch})},
+			want:  []string{`g0_result [shape=note, style=filled, fillcolor="#e1d5e7", label="NON-INCLUSION VERIFIED (EmptyBranchSlot)`},
+		},
+		{
+			name:  "failure note",
+			graph: Graph{Title: `say "hi"` + "\n" + `C:\trie`, Result: trie.VerifyProof(common.HexToHash("0x01"), []byte{0x12}, [][]byte{branch})},
+			want: []string{
+				`label="say \"hi\"\nC:\\trie";`,
+				`label="VERIFICATION FAILED at step 0: proof node 0x0000000000000000000000000000000000000000000000000000000000000001 missing"`,
+			},
+		},
+		{
+			name:  "trie",
+			graph: Graph{Title: "Trie", Root: tr.Root()},
+			want: []string{
+				tr.Hash().Hex(),
+				"inline (embedded in parent, 3 bytes)",
+				`g0_n0:c0:s -> g0_n1 [style=dashed, label="inline"];`,
+				`g0_n0:c1:s -> g0_n2 [label="hash"];`,
+			},
+		},
+		{
+			name:  "empty trie",
+			graph: Graph{Title: "Empty"},
+			want:  []string{`g0_empty [shape=note, label="(empty trie)"];`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteDOT(&b, []Graph{tt.graph}); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			if !strings.HasPrefix(out, "digraph gethtried {\n") || !strings.HasSuffix(out, "}\n") {
+				t.Fatalf("not a DOT digraph:\n%s", out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDOTEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`a "quoted" word`, `a \"quoted\" word`},
+		{`C:\path`, `C:\\path`},
+		{"two\nlines", `two\nlines`},
+		{"ünïcode → ok", "ünïcode → ok"},
+	}
+	for _, tt := range tests {
+		if got := dotEscape(tt.in); got != tt.want {
+			t.Errorf("dotEscape(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
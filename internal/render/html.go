@@ -0,0 +1,226 @@
+package render
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+//go:embed explorer.html
+var explorerTemplate string
+
+var explorerPage = template.Must(template.New("explorer").Parse(explorerTemplate))
+
+const htmlHashWidth = 18
+
+type htmlPage struct {
+	Title  string
+	Graphs []htmlGraph
+}
+
+type htmlGraph struct {
+	Title   string
+	Root    string
+	Key     string
+	Status  string
+	Outcome string
+	Node    *htmlNode
+}
+
+type htmlNode struct {
+	Type       string
+	Hash       string
+	ShortHash  string
+	Inline     bool
+	Size       int
+	RLP        string
+	KeyBefore  string
+	KeySegment string
+	KeyAfter   string
+	Path       string
+	Value      string
+	Slots      []htmlSlot
+	Next       *htmlSlot
+	Note       string
+	Children   []*htmlNode
+}
+
+type htmlSlot struct {
+	Label    string
+	Ref      string
+	ShortRef string
+	RefRLP   string
+	NodeRLP  string
+	Empty    bool
+	Inline   bool
+	Followed bool
+}
+
+func WriteHTML(w io.Writer, title string, graphs []Graph) error {
+	page := htmlPage{Title: title}
+	for _, g := range graphs {
+		if g.Result != nil {
+			page.Graphs = append(page.Graphs, newHTMLPath(g.Title, g.Result))
+		} else {
+			page.Graphs = append(page.Graphs, newHTMLTrie(g.Title, g.Root))
+		}
+	}
+	return explorerPage.Execute(w, page)
+}
+
+func newHTMLPath(title string, result *trie.VerificationResult) htmlGraph {
+	g := htmlGraph{Title: title, Root: result.Root.Hex(), Key: hexutil.Encode(result.Key)}
+	switch {
+	case !result.Verified:
+		g.Status = "failed"
+		g.Outcome = fmt.Sprintf("VERIFICATION FAILED at step %d: %s", result.FailedStep, result.Reason)
+	case result.Exclusion != nil:
+		g.Status = "excluded"
+		g.Outcome = fmt.Sprintf("NON-INCLUSION VERIFIED (%s): %s", result.Exclusion.Kind, result.Exclusion.String())
+	default:
+		g.Status = "included"
+		g.Outcome = fmt.Sprintf("INCLUSION VERIFIED: %d byte value", len(result.Value))
+	}
+
+	var parent *htmlNode
+	for i, step := range result.Steps {
+		n := newHTMLNode(step.Node, step.Hash, step.Inline, step.Raw)
+		n.KeyBefore = result.Path[:step.Depth]
+		n.KeySegment = step.Consumed
+		n.KeyAfter = result.Path[step.Depth+len(step.Consumed):]
+
+		var nextRaw []byte
+		if i+1 < len(result.Steps) {
+			nextRaw = result.Steps[i+1].Raw
+		}
+		switch cur := step.Node.(type) {
+		case *trie.BranchNode:
+			for j, child := range cur.Children {
+				n.Slots[j] = newHTMLSlot(fmt.Sprintf("%x", j), child, nil)
+				if j == step.ChildIndex {
+					n.Slots[j].Followed = true
+					n.Slots[j].NodeRLP = hexOrEmpty(nextRaw)
+				}
+			}
+		case *trie.ExtensionNode:
+			next := newHTMLSlot("next", cur.NextNode, nextRaw)
+			next.Followed = step.ChildIndex < 0 && !step.ChildRef.Empty()
+			n.Next = &next
+		}
+
+		if i == len(result.Steps)-1 {
+			n.Note = g.Outcome
+		}
+		if parent == nil {
+			g.Node = n
+		} else {
+			parent.Children = append(parent.Children, n)
+		}
+		parent = n
+	}
+	return g
+}
+
+func newHTMLTrie(title string, root trie.Node) htmlGraph {
+	g := htmlGraph{Title: title, Status: "trie"}
+	if root == nil {
+		g.Outcome = "(empty trie)"
+		return g
+	}
+	g.Root = root.Hash().Hex()
+
+	var walk func(n trie.Node, hash common.Hash, inline bool, prefix string) *htmlNode
+	walk = func(n trie.Node, hash common.Hash, inline bool, prefix string) *htmlNode {
+		out := newHTMLNode(n, hash, inline, n.Encode())
+		out.KeyBefore = prefix
+
+		switch cur := n.(type) {
+		case *trie.BranchNode:
+			for i, child := range cur.Children {
+				var childRaw []byte
+				if child.Node != nil {
+					childRaw = child.Node.Encode()
+				}
+				out.Slots[i] = newHTMLSlot(fmt.Sprintf("%x", i), child, childRaw)
+				if child.Node != nil {
+					out.Children = append(out.Children, walk(child.Node, common.BytesToHash(child.Hash), child.Inline(), prefix+fmt.Sprintf("%x", i)))
+				}
+			}
+		case *trie.ExtensionNode:
+			var nextRaw []byte
+			if cur.NextNode.Node != nil {
+				nextRaw = cur.NextNode.Node.Encode()
+			}
+			next := newHTMLSlot("next", cur.NextNode, nextRaw)
+			out.Next = &next
+			out.KeySegment = out.Path
+			if cur.NextNode.Node != nil {
+				out.Children = append(out.Children, walk(cur.NextNode.Node, common.BytesToHash(cur.NextNode.Hash), cur.NextNode.Inline(), prefix+out.Path))
+			}
+		case *trie.LeafNode:
+			out.KeySegment = out.Path
+		}
+		return out
+	}
+	g.Node = walk(root, root.Hash(), false, "")
+	return g
+}
+
+func newHTMLNode(n trie.Node, hash common.Hash, inline bool, raw []byte) *htmlNode {
+	out := &htmlNode{
+		Type:   n.Type(),
+		Inline: inline,
+		Size:   len(raw),
+		RLP:    hexutil.Encode(raw),
+	}
+	if !inline {
+		out.Hash = hash.Hex()
+		out.ShortHash = Abbreviate(out.Hash, htmlHashWidth)
+	}
+
+	switch cur := n.(type) {
+	case *trie.BranchNode:
+		out.Slots = make([]htmlSlot, 16)
+		if len(cur.Value) > 0 {
+			out.Value = hexutil.Encode(cur.Value)
+		}
+	case *trie.ExtensionNode:
+		out.Path, _ = trie.DecodeHP(cur.SharedPath)
+	case *trie.LeafNode:
+		out.Path, _ = trie.DecodeHP(cur.PathEnd)
+		out.Value = hexutil.Encode(cur.Value)
+	}
+	return out
+}
+
+func newHTMLSlot(label string, ref trie.Ref, nodeRaw []byte) htmlSlot {
+	slot := htmlSlot{Label: label, Empty: ref.Empty(), Inline: ref.Inline()}
+	switch {
+	case ref.Empty():
+		slot.RefRLP = "0x80"
+	case ref.Inline():
+		slot.Ref = hexutil.Encode(ref.Raw)
+		slot.RefRLP = slot.Ref
+		slot.NodeRLP = slot.Ref
+	default:
+		slot.Ref = hexutil.Encode(ref.Hash)
+		encoded, _ := rlp.EncodeToBytes(ref.Hash)
+		slot.RefRLP = hexutil.Encode(encoded)
+		slot.NodeRLP = hexOrEmpty(nodeRaw)
+	}
+	slot.ShortRef = Abbreviate(slot.Ref, htmlHashWidth)
+	return slot
+}
+
+func hexOrEmpty(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return hexutil.Encode(b)
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestWriteHTML(t *testing.T) {
+	included := inlineProof(t)
+	branch := included.Steps[0].Raw
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x01}, []byte{0x01})
+	tr.Put([]byte{0x12}, make([]byte, 40))
+
+	tests := []struct {
+		name  string
+		graph Graph
+		want  []string
+		skip  []string
+	}{
+		{
+			name:  "inline step",
+			graph: Graph{Title: "<proof>", Result: included},
+			want: []string{
+				"&lt;proof&gt;",
+				"INCLUSION VERIFIED: 1 byte value",
+				`title="` + included.Root.Hex() + `"`,
+				"<b>Leaf</b> inline (embedded in parent)",
+				hexutil.Encode(included.Steps[1].Raw),
+			},
+			skip: []string{"<proof>", crypto.Keccak256Hash(included.Steps[1].Raw).Hex()},
+		},
+		{
+			name:  "exclusion",
+			graph: Graph{Title: "Absent", Result: trie.VerifyProof(included.Root, []byte{0x22}, [][]byte{branch})},
+			want:  []string{"NON-INCLUSION VERIFIED (EmptyBranchSlot)"},
+		},
+		{
+			name:  "failure",
+			graph: Graph{Title: "Failed", Result: trie.VerifyProof(common.HexToHash("0x01"), []byte{0x12}, [][]byte{branch})},
+			want:  []string{"VERIFICATION FAILED at step 0"},
+		},
+		{
+			name:  "trie",
+			graph: Graph{Title: "Trie", Root: tr.Root()},
+			want:  []string{`title="` + tr.Hash().Hex() + `"`, "<b>Leaf</b> inline (embedded in parent)"},
+		},
+		{
+			name:  "empty trie",
+			graph: Graph{Title: "Empty"},
+			want:  []string{"(empty trie)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteHTML(&b, "gethtried", []Graph{tt.graph}); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q", want)
+				}
+			}
+			for _, skip := range tt.skip {
+				if strings.Contains(out, skip) {
+					t.Errorf("output contains unescaped %q", skip)
+				}
+			}
+		})
+	}
+}
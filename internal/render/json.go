@@ -0,0 +1,240 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+const SchemaVersion = "gethtried/v1"
+
+type BlockJSON struct {
+	Number       uint64      `json:"number"`
+	Hash         common.Hash `json:"hash"`
+	StateRoot    common.Hash `json:"stateRoot"`
+	TxRoot       common.Hash `json:"transactionsRoot"`
+	ReceiptsRoot common.Hash `json:"receiptsRoot"`
+}
+
+type AccountJSON struct {
+	Nonce       uint64      `json:"nonce"`
+	Balance     string      `json:"balance"`
+	StorageRoot common.Hash `json:"storageRoot"`
+	CodeHash    common.Hash `json:"codeHash"`
+}
+
+// ReceiptJSON holds the consensus fields of a receipt, which are the only
+// ones committed to by the receipt trie. Derived fields such as gasUsed or
+// the transaction and block hashes are not part of the trie value.
+type ReceiptJSON struct {
+	Type              uint8         `json:"type"`
+	Status            *uint64       `json:"status,omitempty"`
+	PostState         hexutil.Bytes `json:"root,omitempty"`
+	CumulativeGasUsed uint64        `json:"cumulativeGasUsed"`
+	Bloom             types.Bloom   `json:"logsBloom"`
+	Logs              []LogJSON     `json:"logs"`
+}
+
+type LogJSON struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type NodeJSON struct {
+	Hash     common.Hash   `json:"hash"`
+	RLP      hexutil.Bytes `json:"rlp"`
+	Size     int           `json:"size"`
+	Type     string        `json:"type"`
+	Path     string        `json:"path,omitempty"`
+	Children []string      `json:"children,omitempty"`
+	Next     string        `json:"next,omitempty"`
+	Value    hexutil.Bytes `json:"value,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type StepJSON struct {
+	Step        int          `json:"step"`
+	Hash        *common.Hash `json:"hash,omitempty"`
+	Type        string       `json:"type"`
+	Inline      bool         `json:"inline"`
+	Depth       int          `json:"depth"`
+	Consumed    string       `json:"consumed"`
+	ChildIndex  *int         `json:"childIndex,omitempty"`
+	ChildRef    string       `json:"childRef,omitempty"`
+	ChildInline bool         `json:"childInline,omitempty"`
+}
+
+type ExclusionJSON struct {
+	Kind       trie.ExclusionKind `json:"kind"`
+	Step       int                `json:"step"`
+	Depth      int                `json:"depth"`
+	Nibble     *int               `json:"nibble,omitempty"`
+	NodePath   string             `json:"nodePath,omitempty"`
+	TargetPath string             `json:"targetPath,omitempty"`
+	Reason     string             `json:"reason"`
+}
+
+type FailureJSON struct {
+	Step   int    `json:"step"`
+	Reason string `json:"reason"`
+}
+
+type ProofJSON struct {
+	Root      common.Hash    `json:"root"`
+	Key       hexutil.Bytes  `json:"key"`
+	Path      string         `json:"path"`
+	Verified  bool           `json:"verified"`
+	Included  bool           `json:"included"`
+	Value     hexutil.Bytes  `json:"value,omitempty"`
+	Exclusion *ExclusionJSON `json:"exclusion,omitempty"`
+	Failure   *FailureJSON   `json:"failure,omitempty"`
+	Nodes     []NodeJSON     `json:"nodes"`
+	Steps     []StepJSON     `json:"steps"`
+}
+
+func NewBlockJSON(header *types.Header) *BlockJSON {
+	return &BlockJSON{
+		Number:       header.Number.Uint64(),
+		Hash:         header.Hash(),
+		StateRoot:    header.Root,
+		TxRoot:       header.TxHash,
+		ReceiptsRoot: header.ReceiptHash,
+	}
+}
+
+func NewAccountJSON(account *trie.Account) *AccountJSON {
+	return &AccountJSON{
+		Nonce:       account.Nonce,
+		Balance:     account.Balance.String(),
+		StorageRoot: account.Root,
+		CodeHash:    account.CodeHash,
+	}
+}
+
+func NewReceiptJSON(receipt *types.Receipt) *ReceiptJSON {
+	out := &ReceiptJSON{
+		Type:              receipt.Type,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Bloom:             receipt.Bloom,
+		Logs:              make([]LogJSON, 0, len(receipt.Logs)),
+	}
+	if len(receipt.PostState) > 0 {
+		out.PostState = receipt.PostState
+	} else {
+		status := receipt.Status
+		out.Status = &status
+	}
+	for _, log := range receipt.Logs {
+		out.Logs = append(out.Logs, LogJSON{Address: log.Address, Topics: log.Topics, Data: log.Data})
+	}
+	return out
+}
+
+func NewProofJSON(result *trie.VerificationResult, proof [][]byte) *ProofJSON {
+	out := &ProofJSON{
+		Root:     result.Root,
+		Key:      result.Key,
+		Path:     result.Path,
+		Verified: result.Verified,
+		Included: result.Exists(),
+		Value:    result.Value,
+		Nodes:    make([]NodeJSON, 0, len(proof)),
+		Steps:    make([]StepJSON, 0, len(result.Steps)),
+	}
+
+	for _, raw := range proof {
+		out.Nodes = append(out.Nodes, newNodeJSON(raw))
+	}
+
+	for i, step := range result.Steps {
+		s := StepJSON{
+			Step:     i,
+			Type:     step.Node.Type(),
+			Inline:   step.Inline,
+			Depth:    step.Depth,
+			Consumed: step.Consumed,
+		}
+		if !step.Inline {
+			hash := step.Hash
+			s.Hash = &hash
+		}
+		if step.ChildIndex >= 0 {
+			index := step.ChildIndex
+			s.ChildIndex = &index
+		}
+		if !step.ChildRef.Empty() {
+			s.ChildRef = step.ChildRef.String()
+			s.ChildInline = step.ChildRef.Inline()
+		}
+		out.Steps = append(out.Steps, s)
+	}
+
+	if e := result.Exclusion; e != nil {
+		out.Exclusion = &ExclusionJSON{
+			Kind:       e.Kind,
+			Step:       e.Step,
+			Depth:      e.Depth,
+			NodePath:   e.NodePath,
+			TargetPath: e.TargetPath,
+			Reason:     e.String(),
+		}
+		if e.Nibble >= 0 {
+			nibble := e.Nibble
+			out.Exclusion.Nibble = &nibble
+		}
+	}
+
+	if !result.Verified {
+		out.Failure = &FailureJSON{Step: result.FailedStep, Reason: result.Reason}
+	}
+
+	return out
+}
+
+func newNodeJSON(raw []byte) NodeJSON {
+	out := NodeJSON{
+		Hash: crypto.Keccak256Hash(raw),
+		RLP:  raw,
+		Size: len(raw),
+	}
+
+	node, err := trie.ParseNode(raw)
+	if err != nil {
+		out.Type = "Invalid"
+		out.Error = err.Error()
+		return out
+	}
+	out.Type = node.Type()
+
+	switch n := node.(type) {
+	case *trie.BranchNode:
+		out.Children = make([]string, 16)
+		for i, child := range n.Children {
+			if !child.Empty() {
+				out.Children[i] = child.String()
+			}
+		}
+		out.Value = n.Value
+	case *trie.ExtensionNode:
+		out.Path, _ = trie.DecodeHP(n.SharedPath)
+		out.Next = n.NextNode.String()
+	case *trie.LeafNode:
+		out.Path, _ = trie.DecodeHP(n.PathEnd)
+		out.Value = n.Value
+	}
+	return out
+}
+
+func WriteJSON(w io.Writer, v interface{}, compact bool) error {
+	encoder := json.NewEncoder(w)
+	if !compact {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
@@ -0,0 +1,128 @@
+package render
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+// jsonKeys returns the sorted field names v marshals to.
+func jsonKeys(t *testing.T, v interface{}) []string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestProofJSONSchema(t *testing.T) {
+	included := inlineProof(t)
+	branch := included.Steps[0].Raw
+	absent := trie.VerifyProof(included.Root, []byte{0x22}, [][]byte{branch})
+	failed := trie.VerifyProof(common.HexToHash("0x01"), []byte{0x12}, [][]byte{branch})
+
+	tests := []struct {
+		name   string
+		result *trie.VerificationResult
+		proof  []string
+		steps  [][]string
+	}{
+		{
+			name:   "inclusion through an inline leaf",
+			result: included,
+			proof:  []string{"included", "key", "nodes", "path", "root", "steps", "value", "verified"},
+			steps: [][]string{
+				{"childIndex", "childInline", "childRef", "consumed", "depth", "hash", "inline", "step", "type"},
+				{"consumed", "depth", "inline", "step", "type"},
+			},
+		},
+		{
+			name:   "exclusion",
+			result: absent,
+			proof:  []string{"exclusion", "included", "key", "nodes", "path", "root", "steps", "verified"},
+			steps: [][]string{
+				{"childIndex", "consumed", "depth", "hash", "inline", "step", "type"},
+			},
+		},
+		{
+			name:   "failure",
+			result: failed,
+			proof:  []string{"failure", "included", "key", "nodes", "path", "root", "steps", "verified"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewProofJSON(tt.result, [][]byte{branch})
+			if keys := jsonKeys(t, out); !reflect.DeepEqual(keys, tt.proof) {
+				t.Fatalf("proof fields %v, want %v", keys, tt.proof)
+			}
+			if len(out.Steps) != len(tt.steps) {
+				t.Fatalf("%d steps, want %d", len(out.Steps), len(tt.steps))
+			}
+			for i, want := range tt.steps {
+				if keys := jsonKeys(t, out.Steps[i]); !reflect.DeepEqual(keys, want) {
+					t.Errorf("step %d fields %v, want %v", i, keys, want)
+				}
+			}
+		})
+	}
+
+	exclusion := jsonKeys(t, NewProofJSON(absent, nil).Exclusion)
+	if want := []string{"depth", "kind", "nibble", "reason", "step"}; !reflect.DeepEqual(exclusion, want) {
+		t.Fatalf("exclusion fields %v, want %v", exclusion, want)
+	}
+	node := jsonKeys(t, NewProofJSON(included, [][]byte{branch}).Nodes[0])
+	if want := []string{"children", "hash", "rlp", "size", "type"}; !reflect.DeepEqual(node, want) {
+		t.Fatalf("node fields %v, want %v", node, want)
+	}
+}
+
+func TestReceiptJSONSchema(t *testing.T) {
+	log := &types.Log{Address: common.HexToAddress("0x01"), Topics: []common.Hash{{}}, Data: []byte{0x01}, TxHash: common.HexToHash("0x02"), BlockNumber: 7}
+
+	tests := []struct {
+		name    string
+		receipt *types.Receipt
+		keys    []string
+	}{
+		{
+			name:    "post-Byzantium",
+			receipt: &types.Receipt{Type: types.DynamicFeeTxType, Status: 1, CumulativeGasUsed: 21000, Logs: []*types.Log{log}, GasUsed: 21000, TxHash: common.HexToHash("0x02"), BlockNumber: big.NewInt(7)},
+			keys:    []string{"cumulativeGasUsed", "logs", "logsBloom", "status", "type"},
+		},
+		{
+			name:    "pre-Byzantium",
+			receipt: &types.Receipt{PostState: common.HexToHash("0x03").Bytes(), CumulativeGasUsed: 21000},
+			keys:    []string{"cumulativeGasUsed", "logs", "logsBloom", "root", "type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewReceiptJSON(tt.receipt)
+			if keys := jsonKeys(t, out); !reflect.DeepEqual(keys, tt.keys) {
+				t.Fatalf("receipt fields %v, want %v", keys, tt.keys)
+			}
+			for _, l := range out.Logs {
+				if keys := jsonKeys(t, l); !reflect.DeepEqual(keys, []string{"address", "data", "topics"}) {
+					t.Fatalf("log fields %v", keys)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+type MergedPath struct {
+	Label  string
+	Value  string
+	Result *trie.VerificationResult
+}
+
+// mergedRenderer keys nodes by their nibble path from the root, which also
+// identifies inline nodes that have no hash of their own.
+type mergedRenderer struct {
+	shared map[string]int
+	ends   map[string][]string
+}
+
+// RenderMergedPaths draws the union of several proof paths through the same
+// trie as one tree. Nodes on more than one path are drawn once and marked
+// with the number of paths that share them.
+func RenderMergedPaths(root trie.Node, paths []MergedPath) {
+	r := &mergedRenderer{
+		shared: make(map[string]int),
+		ends:   make(map[string][]string),
+	}
+	for _, p := range paths {
+		for _, step := range p.Result.Steps {
+			r.shared[p.Result.Path[:step.Depth]]++
+		}
+		// A path that failed before reaching its first node ends at the root.
+		last := ""
+		if n := len(p.Result.Steps); n > 0 {
+			last = p.Result.Path[:p.Result.Steps[n-1].Depth]
+		}
+		r.ends[last] = append(r.ends[last], mergedOutcome(p))
+	}
+
+	fmt.Printf("--- Merged Trie Fragment (%d paths, %d unique nodes) ---\n", len(paths), len(r.shared))
+	if root == nil {
+		fmt.Println("└── (empty trie)")
+		for _, p := range paths {
+			fmt.Printf("    => %s\n", mergedOutcome(p))
+		}
+		return
+	}
+	r.walk(root, "", "", root.Hash().Hex(), "", true)
+}
+
+func (r *mergedRenderer) walk(n trie.Node, path, label, reference, indent string, last bool) {
+	prefix, childIndent := "├── ", indent+"│   "
+	if last {
+		prefix, childIndent = "└── ", indent+"    "
+	}
+
+	sharedNote := ""
+	if count := r.shared[path]; count > 1 {
+		sharedNote = fmt.Sprintf(" [shared by %d paths]", count)
+	}
+
+	switch cur := n.(type) {
+	case *trie.BranchNode:
+		fmt.Printf("%s%s%sBranch %s%s\n", indent, prefix, label, reference, sharedNote)
+
+		var resolved []int
+		unresolved := 0
+		for i, child := range cur.Children {
+			switch {
+			case child.Node != nil:
+				resolved = append(resolved, i)
+			case !child.Empty():
+				unresolved++
+			}
+		}
+		r.printEnds(path, childIndent)
+		for j, i := range resolved {
+			child := cur.Children[i]
+			lastChild := j == len(resolved)-1 && unresolved == 0
+			r.walk(child.Node, fmt.Sprintf("%s%x", path, i), fmt.Sprintf("[%x] ", i), trieReference(child), childIndent, lastChild)
+		}
+		if unresolved > 0 {
+			fmt.Printf("%s└── (%d other children not in the proofs)\n", childIndent, unresolved)
+		}
+
+	case *trie.ExtensionNode:
+		sharedNibbles, _ := trie.DecodeHP(cur.SharedPath)
+		fmt.Printf("%s%s%sExtension '%s' %s%s\n", indent, prefix, label, sharedNibbles, reference, sharedNote)
+		r.printEnds(path, childIndent)
+		if cur.NextNode.Node != nil {
+			r.walk(cur.NextNode.Node, path+sharedNibbles, "", trieReference(cur.NextNode), childIndent, true)
+		}
+
+	case *trie.LeafNode:
+		pathEnd, _ := trie.DecodeHP(cur.PathEnd)
+		fmt.Printf("%s%s%sLeaf '%s' %s%s\n", indent, prefix, label, pathEnd, reference, sharedNote)
+		r.printEnds(path, childIndent)
+	}
+}
+
+func (r *mergedRenderer) printEnds(path, indent string) {
+	for _, outcome := range r.ends[path] {
+		fmt.Printf("%s=> %s\n", indent, outcome)
+	}
+}
+
+func mergedOutcome(p MergedPath) string {
+	result := p.Result
+	switch {
+	case !result.Verified:
+		return fmt.Sprintf("%s: VERIFICATION FAILED at step %d: %s", p.Label, result.FailedStep, result.Reason)
+	case result.Exclusion != nil:
+		return fmt.Sprintf("%s: absent, %s", p.Label, result.Exclusion.String())
+	}
+	if p.Value != "" {
+		return fmt.Sprintf("%s: %s", p.Label, p.Value)
+	}
+	return fmt.Sprintf("%s: %s", p.Label, hexutil.Encode(result.Value))
+}
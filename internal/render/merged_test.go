@@ -0,0 +1,63 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestRenderMergedPaths(t *testing.T) {
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x12}, make([]byte, 40))
+	tr.Put([]byte{0x13}, []byte{0x01})
+	tr.Put([]byte{0x34}, make([]byte, 50))
+	root := tr.Hash()
+
+	var proof [][]byte
+	for _, key := range [][]byte{{0x12}, {0x13}} {
+		proof = append(proof, tr.Prove(key)...)
+	}
+	fragment, err := trie.ProofFragment(root, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []MergedPath{
+		{Label: "slot a", Result: trie.VerifyProof(root, []byte{0x12}, proof)},
+		{Label: "slot b", Value: "1 (uint256)", Result: trie.VerifyProof(root, []byte{0x13}, proof)},
+		{Label: "slot c", Result: trie.VerifyProof(root, []byte{0x56}, proof)},
+		{Label: "slot d", Result: trie.VerifyProof(common.HexToHash("0x01"), []byte{0x12}, proof)},
+	}
+	if len(paths[3].Result.Steps) != 0 {
+		t.Fatalf("path d has %d steps, want a failure before the root", len(paths[3].Result.Steps))
+	}
+	out := captureOutput(t, func() { RenderMergedPaths(fragment, paths) })
+
+	for _, want := range []string{
+		"(4 paths, 4 unique nodes)",
+		"└── Branch " + root.Hex() + " [shared by 3 paths]",
+		"    => slot c: absent, ",
+		"    => slot d: VERIFICATION FAILED at step 0: ",
+		"├── [1] Branch ",
+		"[3] Leaf '' inline (embedded in parent, 3 bytes)",
+		"=> slot a: 0x" + strings.Repeat("00", 40),
+		"=> slot b: 1 (uint256)",
+		"(1 other children not in the proofs)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMergedPathsEmptyTrie(t *testing.T) {
+	paths := []MergedPath{
+		{Label: "slot a", Result: trie.VerifyProof(trie.NewTrie().Hash(), []byte{0x12}, nil)},
+	}
+	out := captureOutput(t, func() { RenderMergedPaths(nil, paths) })
+	if !strings.Contains(out, "└── (empty trie)") || !strings.Contains(out, "=> slot a: absent") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
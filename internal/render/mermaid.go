@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+type mermaidWriter struct {
+	b     bytes.Buffer
+	width int
+	nodes map[string][]string
+}
+
+func WriteMermaid(w io.Writer, graphs []Graph, collapseWidth int) error {
+	m := &mermaidWriter{width: collapseWidth, nodes: make(map[string][]string)}
+	m.b.WriteString("graph TD\n")
+
+	for i, g := range graphs {
+		prefix := fmt.Sprintf("g%d_", i)
+		fmt.Fprintf(&m.b, "  subgraph %scluster[\"%s\"]\n", prefix, mermaidEscape(g.Title))
+		if g.Result != nil {
+			m.writePath(prefix, g.Result)
+		} else {
+			m.writeTrie(prefix, g.Root)
+		}
+		m.b.WriteString("  end\n")
+	}
+
+	for _, nodeType := range []string{"Branch", "Extension", "Leaf"} {
+		fmt.Fprintf(&m.b, "  classDef %s fill:%s,stroke:#555\n", strings.ToLower(nodeType), nodeColors[nodeType])
+	}
+	m.b.WriteString("  classDef excluded fill:#e1d5e7,stroke:#9673a6\n")
+	m.b.WriteString("  classDef failed fill:#f8cecc,stroke:#b85450\n")
+
+	for _, class := range []string{"branch", "extension", "leaf", "excluded", "failed"} {
+		if ids := m.nodes[class]; len(ids) > 0 {
+			fmt.Fprintf(&m.b, "  class %s %s\n", strings.Join(ids, ","), class)
+		}
+	}
+
+	_, err := w.Write(m.b.Bytes())
+	return err
+}
+
+func (m *mermaidWriter) writePath(prefix string, result *trie.VerificationResult) {
+	for i, step := range result.Steps {
+		id := fmt.Sprintf("%sn%d", prefix, i)
+		m.writeNode(id, step.Node, stepReference(step), step.Inline)
+		if i > 0 {
+			prev := result.Steps[i-1]
+			m.writeEdge(fmt.Sprintf("%sn%d", prefix, i-1), id, prev.Consumed, prev.ChildRef)
+		}
+	}
+
+	var note, class string
+	switch {
+	case !result.Verified:
+		note = fmt.Sprintf("VERIFICATION FAILED at step %d: %s", result.FailedStep, result.Reason)
+		class = "failed"
+	case result.Exclusion != nil:
+		note = fmt.Sprintf("NON-INCLUSION VERIFIED (%s)", result.Exclusion.Kind)
+		class = "excluded"
+	default:
+		return
+	}
+
+	noteID := prefix + "result"
+	fmt.Fprintf(&m.b, "    %s[\"%s\"]\n", noteID, mermaidEscape(note))
+	m.nodes[class] = append(m.nodes[class], noteID)
+	if n := len(result.Steps); n > 0 {
+		fmt.Fprintf(&m.b, "    %sn%d -.- %s\n", prefix, n-1, noteID)
+	}
+}
+
+func (m *mermaidWriter) writeTrie(prefix string, root trie.Node) {
+	if root == nil {
+		fmt.Fprintf(&m.b, "    %sempty[\"(empty trie)\"]\n", prefix)
+		return
+	}
+
+	counter := 0
+	var walk func(n trie.Node, reference string, inline bool) string
+	walk = func(n trie.Node, reference string, inline bool) string {
+		id := fmt.Sprintf("%sn%d", prefix, counter)
+		counter++
+		m.writeNode(id, n, reference, inline)
+
+		switch cur := n.(type) {
+		case *trie.BranchNode:
+			for i, child := range cur.Children {
+				if child.Empty() || child.Node == nil {
+					continue
+				}
+				childID := walk(child.Node, trieReference(child), child.Inline())
+				m.writeEdge(id, childID, fmt.Sprintf("%x", i), child)
+			}
+		case *trie.ExtensionNode:
+			if cur.NextNode.Node != nil {
+				sharedNibbles, _ := trie.DecodeHP(cur.SharedPath)
+				childID := walk(cur.NextNode.Node, trieReference(cur.NextNode), cur.NextNode.Inline())
+				m.writeEdge(id, childID, sharedNibbles, cur.NextNode)
+			}
+		}
+		return id
+	}
+	walk(root, root.Hash().Hex(), false)
+}
+
+func (m *mermaidWriter) writeNode(id string, n trie.Node, reference string, inline bool) {
+	lines := []string{n.Type()}
+	if inline {
+		lines = append(lines, reference)
+	} else {
+		lines = append(lines, Abbreviate(reference, m.width))
+	}
+
+	switch cur := n.(type) {
+	case *trie.BranchNode:
+		if len(cur.Value) > 0 {
+			lines = append(lines, "value: "+Abbreviate(hexutil.Encode(cur.Value), m.width))
+		}
+	case *trie.ExtensionNode:
+		sharedNibbles, _ := trie.DecodeHP(cur.SharedPath)
+		lines = append(lines, "path: '"+Abbreviate(sharedNibbles, m.width)+"'")
+	case *trie.LeafNode:
+		pathEnd, _ := trie.DecodeHP(cur.PathEnd)
+		lines = append(lines, "path: '"+Abbreviate(pathEnd, m.width)+"'")
+		lines = append(lines, "value: "+Abbreviate(hexutil.Encode(cur.Value), m.width))
+	}
+
+	for i := range lines {
+		lines[i] = mermaidEscape(lines[i])
+	}
+	fmt.Fprintf(&m.b, "    %s[\"%s\"]\n", id, strings.Join(lines, "<br/>"))
+
+	class := strings.ToLower(n.Type())
+	m.nodes[class] = append(m.nodes[class], id)
+}
+
+func (m *mermaidWriter) writeEdge(from, to, nibbles string, ref trie.Ref) {
+	label := mermaidEscape(Abbreviate(nibbles, m.width))
+	if ref.Inline() {
+		fmt.Fprintf(&m.b, "    %s -.->|\"%s inline\"| %s\n", from, label, to)
+		return
+	}
+	fmt.Fprintf(&m.b, "    %s -->|\"%s\"| %s\n", from, label, to)
+}
+
+func Abbreviate(s string, width int) string {
+	if width <= 0 || len(s) <= width {
+		return s
+	}
+	head := (width + 1) / 2
+	tail := width - head
+	return s[:head] + "…" + s[len(s)-tail:]
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
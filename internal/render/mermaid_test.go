@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestWriteMermaid(t *testing.T) {
+	included := inlineProof(t)
+	branch := included.Steps[0].Raw
+	root := included.Root.Hex()
+
+	tests := []struct {
+		name  string
+		graph Graph
+		width int
+		want  []string
+	}{
+		{
+			name:  "inline step",
+			graph: Graph{Title: `"quoted" <title>`, Result: included},
+			want: []string{
+				`subgraph g0_cluster["#quot;quoted#quot; #lt;title#gt;"]`,
+				`g0_n0["Branch<br/>` + root + `"]`,
+				`g0_n1["Leaf<br/>inline (embedded in parent, 3 bytes)<br/>path: '2'<br/>value: 0x07"]`,
+				`g0_n0 -.->|"1 inline"| g0_n1`,
+				"class g0_n0 branch",
+				"class g0_n1 leaf",
+			},
+		},
+		{
+			name:  "abbreviated hashes keep inline labels whole",
+			graph: Graph{Title: "Proof", Result: included},
+			width: 10,
+			want: []string{
+				`g0_n0["Branch<br/>` + root[:5] + "…" + root[len(root)-5:] + `"]`,
+				"inline (embedded in parent, 3 bytes)",
+			},
+		},
+		{
+			name:  "exclusion",
+			graph: Graph{Title: "Absent", Result: trie.VerifyProof(included.Root, []byte{0x22}, [][]byte{branch})},
+			want:  []string{`g0_result["NON-INCLUSION VERIFIED (EmptyBranchSlot)"]`, "g0_n0 -.- g0_result", "class g0_result excluded"},
+		},
+		{
+			name:  "failure",
+			graph: Graph{Title: "Failed", Result: trie.VerifyProof(common.HexToHash("0x01"), []byte{0x12}, [][]byte{branch})},
+			want:  []string{`g0_result["VERIFICATION FAILED at step 0: proof node`, "class g0_result failed"},
+		},
+		{
+			name:  "empty trie",
+			graph: Graph{Title: "Empty"},
+			want:  []string{`g0_empty["(empty trie)"]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteMermaid(&b, []Graph{tt.graph}, tt.width); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			if !strings.HasPrefix(out, "graph TD\n") {
+				t.Fatalf("not a Mermaid flowchart:\n%s", out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"0x1234567890", 0, "0x1234567890"},
+		{"0x1234567890", 12, "0x1234567890"},
+		{"0x1234567890", 6, "0x1…890"},
+		{"0x1234567890", 5, "0x1…90"},
+	}
+	for _, tt := range tests {
+		if got := Abbreviate(tt.in, tt.width); got != tt.want {
+			t.Errorf("Abbreviate(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func RenderPhysicalPath(result *trie.VerificationResult, proof [][]byte) {
+	fmt.Println("--- Physical Proof View (RPC order) ---")
+	fmt.Printf("Root: %s\n\n", result.Root.Hex())
+
+	usedAt := make(map[common.Hash]int)
+	position := 0
+	expectedPosition := make(map[common.Hash]int)
+	for i, step := range result.Steps {
+		if step.Inline {
+			continue
+		}
+		usedAt[step.Hash] = i
+		expectedPosition[step.Hash] = position
+		position++
+	}
+
+	var (
+		warnings  []string
+		totalSize int
+		usedSize  int
+	)
+	seen := make(map[common.Hash]int)
+
+	fmt.Printf("  %-3s %6s  %-10s %-66s  %s\n", "#", "SIZE", "TYPE", "HASH", "REFERENCED BY")
+	for i, raw := range proof {
+		hash := crypto.Keccak256Hash(raw)
+		totalSize += len(raw)
+
+		nodeType := "Invalid"
+		if node, err := trie.ParseNode(raw); err == nil {
+			nodeType = node.Type()
+		}
+
+		var reference string
+		step, used := usedAt[hash]
+		first, duplicate := seen[hash]
+		switch {
+		case duplicate:
+			reference = fmt.Sprintf("DUPLICATE of #%d", first)
+			warnings = append(warnings, fmt.Sprintf("node #%d duplicates node #%d", i, first))
+		case !used:
+			reference = "UNUSED: not referenced on the key path"
+			warnings = append(warnings, fmt.Sprintf("node #%d (%d bytes) is not referenced by any node on the path", i, len(raw)))
+		default:
+			usedSize += len(raw)
+			reference = physicalReference(result, step)
+			if expected := expectedPosition[hash]; expected != i {
+				reference += fmt.Sprintf(" OUT OF ORDER (expected #%d)", expected)
+				warnings = append(warnings, fmt.Sprintf("node #%d is used at step %d and should be at position #%d", i, step, expected))
+			}
+		}
+		if !duplicate {
+			seen[hash] = i
+		}
+
+		fmt.Printf("  %-3d %6d  %-10s %-66s  %s\n", i, len(raw), nodeType, hash.Hex(), reference)
+	}
+
+	for i, step := range result.Steps {
+		if step.Inline {
+			fmt.Printf("  %-3s %6d  %-10s %-66s  %s (not a separate proof node)\n",
+				"-", len(step.Raw), step.Node.Type(), "inline (embedded in parent)", physicalReference(result, i))
+		}
+	}
+
+	if !result.Verified {
+		warnings = append(warnings, fmt.Sprintf("verification failed at step %d: %s", result.FailedStep, result.Reason))
+	}
+
+	fmt.Printf("\nTotal: %d nodes, %d bytes (used %d bytes, unused %d bytes)\n", len(proof), totalSize, usedSize, totalSize-usedSize)
+	if len(warnings) == 0 {
+		fmt.Println("Proof is minimal and in path order.")
+		return
+	}
+	fmt.Println("Warnings:")
+	for _, warning := range warnings {
+		fmt.Printf("  - %s\n", warning)
+	}
+}
+
+func physicalReference(result *trie.VerificationResult, step int) string {
+	if step == 0 {
+		return "root (step 0)"
+	}
+	parent := result.Steps[step-1]
+	if parent.ChildIndex >= 0 {
+		return fmt.Sprintf("step %d slot '%x' (step %d)", step-1, parent.ChildIndex, step)
+	}
+	return fmt.Sprintf("step %d next (step %d)", step-1, step)
+}
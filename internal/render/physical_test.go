@@ -0,0 +1,84 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestRenderPhysicalPath(t *testing.T) {
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x12}, make([]byte, 40))
+	tr.Put([]byte{0x34}, make([]byte, 50))
+	key := []byte{0x12}
+	proof := tr.Prove(key)
+	if len(proof) != 2 {
+		t.Fatalf("proof has %d nodes, want 2", len(proof))
+	}
+	other := tr.Prove([]byte{0x34})[1]
+
+	tests := []struct {
+		name  string
+		proof [][]byte
+		want  []string
+	}{
+		{
+			name:  "minimal",
+			proof: proof,
+			want: []string{
+				"root (step 0)",
+				"step 0 slot '1' (step 1)",
+				"Proof is minimal and in path order.",
+			},
+		},
+		{
+			name:  "out of order",
+			proof: [][]byte{proof[1], proof[0]},
+			want: []string{
+				"OUT OF ORDER (expected #1)",
+				"node #0 is used at step 1 and should be at position #1",
+			},
+		},
+		{
+			name:  "unused",
+			proof: [][]byte{proof[0], proof[1], other},
+			want: []string{
+				"UNUSED: not referenced on the key path",
+				"node #2 (53 bytes) is not referenced by any node on the path",
+				"used 126 bytes, unused 53 bytes",
+			},
+		},
+		{
+			name:  "duplicate",
+			proof: [][]byte{proof[0], proof[0], proof[1]},
+			want: []string{
+				"DUPLICATE of #0",
+				"node #1 duplicates node #0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := trie.VerifyProof(tr.Hash(), key, tt.proof)
+			out := captureOutput(t, func() { RenderPhysicalPath(result, tt.proof) })
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPhysicalPathInline(t *testing.T) {
+	result := inlineProof(t)
+	out := captureOutput(t, func() { RenderPhysicalPath(result, [][]byte{result.Steps[0].Raw}) })
+
+	if !strings.Contains(out, "inline (embedded in parent)") || !strings.Contains(out, "step 0 slot '1' (step 1) (not a separate proof node)") {
+		t.Errorf("inline step not listed:\n%s", out)
+	}
+	if !strings.Contains(out, "Proof is minimal and in path order.") {
+		t.Errorf("inline step counted as a proof node:\n%s", out)
+	}
+}
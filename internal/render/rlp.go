@@ -0,0 +1,170 @@
+package render
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+const rlpBytesPerLine = 16
+
+func RenderRLPPath(result *trie.VerificationResult) {
+	fmt.Println("--- Annotated RLP Path ---")
+	fmt.Printf("Target Path: %s\n", result.Path)
+
+	for i, step := range result.Steps {
+		reference := fmt.Sprintf("%s (%d bytes, hash-referenced)", step.Hash.Hex(), len(step.Raw))
+		if step.Inline {
+			reference = stepReference(step)
+		}
+		fmt.Printf("\n[Step %d] %s %s\n", i, step.Node.Type(), reference)
+		fmt.Printf("  %-4s  %-*s  %s\n", "OFF", rlpBytesPerLine*2, "BYTES", "MEANING")
+		for _, line := range AnnotateNodeRLP(step.Raw) {
+			fmt.Println(line)
+		}
+	}
+
+	if !result.Verified {
+		fmt.Printf("\nERROR at step %d: %s\n", result.FailedStep, result.Reason)
+	}
+}
+
+func AnnotateNodeRLP(raw []byte) []string {
+	a := &rlpAnnotator{}
+	a.node(raw, 0, "  ")
+	return a.lines
+}
+
+type rlpAnnotator struct {
+	lines []string
+}
+
+func (a *rlpAnnotator) emit(indent string, offset int, data []byte, note string) {
+	if len(data) == 0 {
+		a.lines = append(a.lines, fmt.Sprintf("%s%04x  %-*s  %s", indent, offset, rlpBytesPerLine*2, "", note))
+		return
+	}
+	for start := 0; start < len(data); start += rlpBytesPerLine {
+		end := start + rlpBytesPerLine
+		if end > len(data) {
+			end = len(data)
+		}
+		line := fmt.Sprintf("%s%04x  %-*s", indent, offset+start, rlpBytesPerLine*2, hex.EncodeToString(data[start:end]))
+		if start == 0 {
+			line += "  " + note
+		}
+		a.lines = append(a.lines, strings.TrimRight(line, " "))
+	}
+}
+
+func (a *rlpAnnotator) node(raw []byte, base int, indent string) {
+	kind, content, trailing, err := rlp.Split(raw)
+	if err != nil || kind != rlp.List {
+		a.emit(indent, base, raw, fmt.Sprintf("not an RLP list: %v", err))
+		return
+	}
+	headerLen := len(raw) - len(content) - len(trailing)
+	items, _ := rlp.CountValues(content)
+
+	node, parseErr := trie.ParseNode(raw[:headerLen+len(content)])
+	nodeType := "invalid node"
+	if parseErr == nil {
+		nodeType = node.Type()
+	}
+	a.emit(indent, base, raw[:headerLen], fmt.Sprintf("%s, %d items -> %s node", describeHeader(raw[:headerLen], len(content)), items, nodeType))
+
+	offset := base + headerLen
+	rest := content
+	for i := 0; len(rest) > 0; i++ {
+		itemKind, itemContent, next, err := rlp.Split(rest)
+		if err != nil {
+			a.emit(indent, offset, rest, fmt.Sprintf("malformed item %d: %v", i, err))
+			return
+		}
+		item := rest[:len(rest)-len(next)]
+		header := item[:len(item)-len(itemContent)]
+
+		label := itemLabel(node, i, items)
+		switch {
+		case itemKind == rlp.List:
+			a.emit(indent, offset, nil, fmt.Sprintf("%s: embedded node (inline, %d bytes < 32)", label, len(item)))
+			a.node(item, offset, indent+"    ")
+		case items == 2 && i == 0:
+			a.stringItem(indent, offset, header, itemContent, label, describeHP(itemContent))
+		case len(itemContent) == 0:
+			a.emit(indent, offset, item, label+": empty string (0x80)")
+		case items == 17 && i < 16, items == 2 && i == 1 && nodeType == "Extension":
+			a.stringItem(indent, offset, header, itemContent, label, fmt.Sprintf("keccak256 hash reference (%d bytes)", len(itemContent)))
+		default:
+			a.stringItem(indent, offset, header, itemContent, label, fmt.Sprintf("value (%d bytes)", len(itemContent)))
+		}
+
+		offset += len(item)
+		rest = next
+	}
+}
+
+func (a *rlpAnnotator) stringItem(indent string, offset int, header, content []byte, label, meaning string) {
+	if len(header) == 0 {
+		a.emit(indent, offset, content, fmt.Sprintf("%s: single byte < 0x80, encoded as itself; %s", label, meaning))
+		return
+	}
+	a.emit(indent, offset, header, fmt.Sprintf("%s: %s", label, describeHeader(header, len(content))))
+	a.emit(indent, offset+len(header), content, "  "+meaning)
+}
+
+func itemLabel(node trie.Node, index, items int) string {
+	if items == 17 {
+		if index == 16 {
+			return "[16] branch value"
+		}
+		return fmt.Sprintf("[%d] child '%x'", index, index)
+	}
+	if items == 2 {
+		if index == 0 {
+			return "[0] path"
+		}
+		if _, ok := node.(*trie.ExtensionNode); ok {
+			return "[1] next node"
+		}
+		return "[1] leaf value"
+	}
+	return fmt.Sprintf("[%d]", index)
+}
+
+func describeHeader(header []byte, contentLen int) string {
+	if len(header) == 0 {
+		return "single byte, no header"
+	}
+	prefix := header[0]
+	switch {
+	case prefix <= 0xb7:
+		return fmt.Sprintf("string header 0x%02x = 0x80 + %d bytes", prefix, contentLen)
+	case prefix <= 0xbf:
+		return fmt.Sprintf("string header 0x%02x = 0xb7 + %d length byte(s), length %d", prefix, prefix-0xb7, contentLen)
+	case prefix <= 0xf7:
+		return fmt.Sprintf("list header 0x%02x = 0xc0 + %d payload bytes", prefix, contentLen)
+	}
+	return fmt.Sprintf("list header 0x%02x = 0xf7 + %d length byte(s), payload %d bytes", prefix, prefix-0xf7, contentLen)
+}
+
+func describeHP(path []byte) string {
+	if len(path) == 0 {
+		return "hex-prefix path: empty (invalid)"
+	}
+	flag := path[0] >> 4
+	meaning := map[byte]string{
+		0: "extension, even length, low nibble is padding",
+		1: "extension, odd length, low nibble is first path nibble",
+		2: "leaf, even length, low nibble is padding",
+		3: "leaf, odd length, low nibble is first path nibble",
+	}[flag]
+	if meaning == "" {
+		return fmt.Sprintf("hex-prefix flag nibble %d: invalid", flag)
+	}
+	nibbles, _ := trie.DecodeHP(path)
+	return fmt.Sprintf("hex-prefix flag nibble %d (%s) -> path '%s' (%d nibbles)", flag, meaning, nibbles, len(nibbles))
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRLPPathInline(t *testing.T) {
+	result := inlineProof(t)
+	out := captureOutput(t, func() { RenderRLPPath(result) })
+
+	for _, want := range []string{
+		"[Step 0] Branch " + result.Steps[0].Hash.Hex(),
+		"[Step 1] Leaf inline (embedded in parent, 3 bytes)",
+		"[1] child '1': embedded node (inline, 3 bytes < 32)",
+		"[3] child '3': string header 0xa0 = 0x80 + 32 bytes",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnnotateNodeRLP(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want []string
+	}{
+		{
+			name: "odd leaf",
+			raw:  encode(t, [][]byte{{0x32}, {0x07}}),
+			want: []string{
+				"  0000  c2                                list header 0xc2 = 0xc0 + 2 payload bytes, 2 items -> Leaf node",
+				"  0001  32                                [0] path: single byte < 0x80, encoded as itself; hex-prefix flag nibble 3 (leaf, odd length, low nibble is first path nibble) -> path '2' (1 nibbles)",
+				"  0002  07                                [1] leaf value: single byte < 0x80, encoded as itself; value (1 bytes)",
+			},
+		},
+		{
+			name: "even extension",
+			raw:  encode(t, [][]byte{{0x00, 0xab}, make([]byte, 32)}),
+			want: []string{
+				"list header 0xe4 = 0xc0 + 36 payload bytes, 2 items -> Extension node",
+				"[0] path: string header 0x82 = 0x80 + 2 bytes",
+				"hex-prefix flag nibble 0 (extension, even length, low nibble is padding) -> path 'ab' (2 nibbles)",
+				"[1] next node: string header 0xa0 = 0x80 + 32 bytes",
+				"keccak256 hash reference (32 bytes)",
+			},
+		},
+		{
+			name: "long leaf value",
+			raw:  encode(t, [][]byte{{0x20}, make([]byte, 60)}),
+			want: []string{
+				"list header 0xf8 = 0xf7 + 1 length byte(s), payload 63 bytes",
+				"[1] leaf value: string header 0xb8 = 0xb7 + 1 length byte(s), length 60",
+				"value (60 bytes)",
+			},
+		},
+		{
+			name: "not a list",
+			raw:  []byte{0x83, 0x01, 0x02, 0x03},
+			want: []string{"not an RLP list"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := strings.Join(AnnotateNodeRLP(tt.raw), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("annotation does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
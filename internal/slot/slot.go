@@ -0,0 +1,282 @@
+package slot
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+type Step struct {
+	Expr    string      `json:"expr"`
+	Formula string      `json:"formula"`
+	Slot    common.Hash `json:"slot"`
+}
+
+type Derivation struct {
+	Expr  string
+	Key   common.Hash
+	Steps []Step
+}
+
+func Parse(expr string) (*Derivation, error) {
+	p := &parser{input: expr}
+	key, canonical, err := p.parseSlot()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.input[p.pos:], p.pos)
+	}
+	return &Derivation{Expr: canonical, Key: key, Steps: p.steps}, nil
+}
+
+func Mapping(slot common.Hash, key []byte) common.Hash {
+	return crypto.Keccak256Hash(key, slot.Bytes())
+}
+
+func ArrayElement(slot common.Hash, index, elementSize *big.Int) common.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(slot.Bytes()))
+	return Add(common.BigToHash(base), new(big.Int).Mul(index, elementSize))
+}
+
+// ERC7201 returns the base slot of a namespace as defined by ERC-7201:
+// keccak256(abi.encode(uint256(keccak256(namespace)) - 1)) & ~bytes32(uint256(0xff)).
+func ERC7201(namespace string) common.Hash {
+	id := new(big.Int).Sub(crypto.Keccak256Hash([]byte(namespace)).Big(), big.NewInt(1))
+	base := crypto.Keccak256Hash(common.BigToHash(id.And(id, maxUint256)).Bytes())
+	base[common.HashLength-1] = 0
+	return base
+}
+
+func Add(slot common.Hash, offset *big.Int) common.Hash {
+	sum := new(big.Int).Add(slot.Big(), offset)
+	return common.BigToHash(sum.And(sum, maxUint256))
+}
+
+type parser struct {
+	input string
+	pos   int
+	steps []Step
+}
+
+func (p *parser) parseSlot() (common.Hash, string, error) {
+	p.skipSpaces()
+	switch {
+	case p.consume("mapping("):
+		return p.parseMapping()
+	case p.consume("array("):
+		return p.parseArray()
+	case p.consume("field("):
+		return p.parseField()
+	case p.consume("erc7201:"):
+		return p.parseNamespace()
+	}
+
+	value, text, err := p.parseNumber()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	return common.BigToHash(value), text, nil
+}
+
+func (p *parser) parseMapping() (common.Hash, string, error) {
+	base, baseExpr, err := p.parseSlot()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect(")"); err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect("["); err != nil {
+		return common.Hash{}, "", err
+	}
+	key, keyText, err := p.parseKey()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect("]"); err != nil {
+		return common.Hash{}, "", err
+	}
+
+	result := Mapping(base, key)
+	expr := fmt.Sprintf("mapping(%s)[%s]", baseExpr, keyText)
+	p.steps = append(p.steps, Step{
+		Expr:    expr,
+		Formula: fmt.Sprintf("keccak256(%s . %s)", hexutil.Encode(key), base.Hex()),
+		Slot:    result,
+	})
+	return result, expr, nil
+}
+
+func (p *parser) parseArray() (common.Hash, string, error) {
+	base, baseExpr, err := p.parseSlot()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+
+	elementSize := big.NewInt(1)
+	sizeText := ""
+	p.skipSpaces()
+	if p.consume(",") {
+		elementSize, sizeText, err = p.parseNumber()
+		if err != nil {
+			return common.Hash{}, "", err
+		}
+		if elementSize.Sign() == 0 {
+			return common.Hash{}, "", fmt.Errorf("array element size must be at least 1 slot")
+		}
+		sizeText = ", " + sizeText
+	}
+	if err := p.expect(")"); err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect("["); err != nil {
+		return common.Hash{}, "", err
+	}
+	index, indexText, err := p.parseNumber()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect("]"); err != nil {
+		return common.Hash{}, "", err
+	}
+
+	result := ArrayElement(base, index, elementSize)
+	expr := fmt.Sprintf("array(%s%s)[%s]", baseExpr, sizeText, indexText)
+	formula := fmt.Sprintf("keccak256(%s) + %s", base.Hex(), index)
+	if elementSize.Cmp(big.NewInt(1)) != 0 {
+		formula = fmt.Sprintf("keccak256(%s) + %s * %s", base.Hex(), index, elementSize)
+	}
+	p.steps = append(p.steps, Step{Expr: expr, Formula: formula, Slot: result})
+	return result, expr, nil
+}
+
+func (p *parser) parseField() (common.Hash, string, error) {
+	base, baseExpr, err := p.parseSlot()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect(","); err != nil {
+		return common.Hash{}, "", err
+	}
+	offset, offsetText, err := p.parseNumber()
+	if err != nil {
+		return common.Hash{}, "", err
+	}
+	if err := p.expect(")"); err != nil {
+		return common.Hash{}, "", err
+	}
+
+	result := Add(base, offset)
+	expr := fmt.Sprintf("field(%s, %s)", baseExpr, offsetText)
+	p.steps = append(p.steps, Step{
+		Expr:    expr,
+		Formula: fmt.Sprintf("%s + %s", base.Hex(), offset),
+		Slot:    result,
+	})
+	return result, expr, nil
+}
+
+func (p *parser) parseNamespace() (common.Hash, string, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isTokenChar(p.input[p.pos]) || strings.IndexByte("._-$", p.input[p.pos]) >= 0) {
+		p.pos++
+	}
+	namespace := p.input[start:p.pos]
+	if namespace == "" {
+		return common.Hash{}, "", fmt.Errorf("expected a namespace after \"erc7201:\" at offset %d", start)
+	}
+
+	result := ERC7201(namespace)
+	expr := "erc7201:" + namespace
+	p.steps = append(p.steps, Step{
+		Expr:    expr,
+		Formula: fmt.Sprintf("keccak256(uint256(keccak256(%q)) - 1) & ~0xff", namespace),
+		Slot:    result,
+	})
+	return result, expr, nil
+}
+
+func (p *parser) parseKey() ([]byte, string, error) {
+	p.skipSpaces()
+	if p.consume(`"`) {
+		end := strings.IndexByte(p.input[p.pos:], '"')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string key at offset %d", p.pos-1)
+		}
+		key := p.input[p.pos : p.pos+end]
+		p.pos += end + 1
+		return []byte(key), fmt.Sprintf("%q", key), nil
+	}
+
+	value, text, err := p.parseNumber()
+	if err != nil {
+		return nil, "", err
+	}
+	return common.BigToHash(value).Bytes(), text, nil
+}
+
+func (p *parser) parseNumber() (*big.Int, string, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isTokenChar(p.input[p.pos]) {
+		p.pos++
+	}
+	text := p.input[start:p.pos]
+	if text == "" {
+		if start == len(p.input) {
+			return nil, "", fmt.Errorf("unexpected end of expression")
+		}
+		return nil, "", fmt.Errorf("unexpected %q at offset %d", p.input[start:start+1], start)
+	}
+
+	value, ok := new(big.Int), false
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		value, ok = value.SetString(text[2:], 16)
+	} else {
+		value, ok = value.SetString(text, 10)
+	}
+	if !ok {
+		return nil, "", fmt.Errorf("invalid number %q (must be decimal or hex with 0x prefix)", text)
+	}
+	if value.Cmp(maxUint256) > 0 {
+		return nil, "", fmt.Errorf("number %q does not fit in 32 bytes", text)
+	}
+	return value, text, nil
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(token string) error {
+	p.skipSpaces()
+	if p.consume(token) {
+		return nil
+	}
+	if p.pos == len(p.input) {
+		return fmt.Errorf("expected %q at end of expression", token)
+	}
+	return fmt.Errorf("expected %q at offset %d, found %q", token, p.pos, p.input[p.pos:p.pos+1])
+}
+
+func isTokenChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
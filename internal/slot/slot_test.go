@@ -0,0 +1,122 @@
+package slot
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func word(n int64) []byte {
+	return common.BigToHash(big.NewInt(n)).Bytes()
+}
+
+func plus(slot common.Hash, n int64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(n)))
+}
+
+func TestParse(t *testing.T) {
+	mapping5 := crypto.Keccak256Hash(word(1), word(5))
+	array3 := crypto.Keccak256Hash(word(3))
+	key32 := "0x" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		expr  string
+		key   common.Hash
+		canon string
+		steps int
+	}{
+		{"0", common.Hash{}, "0", 0},
+		{"7", common.BigToHash(big.NewInt(7)), "7", 0},
+		{"0x10", common.BigToHash(big.NewInt(16)), "0x10", 0},
+		{key32, common.HexToHash(key32), key32, 0},
+		{"mapping(5)[1]", mapping5, "mapping(5)[1]", 1},
+		{" mapping( 5 )[ 1 ] ", mapping5, "mapping(5)[1]", 1},
+		{"mapping(4)[0xbeef]", crypto.Keccak256Hash(word(0xbeef), word(4)), "mapping(4)[0xbeef]", 1},
+		{`mapping(2)["abc"]`, crypto.Keccak256Hash([]byte("abc"), word(2)), `mapping(2)["abc"]`, 1},
+		{"mapping(mapping(5)[1])[2]", crypto.Keccak256Hash(word(2), mapping5.Bytes()), "mapping(mapping(5)[1])[2]", 2},
+		{"array(3)[0]", array3, "array(3)[0]", 1},
+		{"array(3)[4]", plus(array3, 4), "array(3)[4]", 1},
+		{"array(3, 2)[4]", plus(array3, 8), "array(3, 2)[4]", 1},
+		{"field(mapping(5)[1], 2)", plus(mapping5, 2), "field(mapping(5)[1], 2)", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			d, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Key != tt.key {
+				t.Errorf("key %s, want %s", d.Key.Hex(), tt.key.Hex())
+			}
+			if d.Expr != tt.canon {
+				t.Errorf("canonical expression %q, want %q", d.Expr, tt.canon)
+			}
+			if len(d.Steps) != tt.steps {
+				t.Fatalf("%d steps, want %d", len(d.Steps), tt.steps)
+			}
+			if tt.steps > 0 && d.Steps[len(d.Steps)-1].Slot != d.Key {
+				t.Errorf("last step slot %s is not the key", d.Steps[len(d.Steps)-1].Slot.Hex())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"abc",
+		"0x" + strings.Repeat("ff", 33),
+		"mapping(5)",
+		"mapping(5)[1",
+		`mapping(5)["abc]`,
+		"array(3)[]",
+		"array(3, 0)[1]",
+		"field(3)",
+		"5 6",
+		"-1",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if d, err := Parse(expr); err == nil {
+				t.Fatalf("expected an error, got key %s", d.Key.Hex())
+			}
+		})
+	}
+}
+
+func TestAddWraps(t *testing.T) {
+	max := common.HexToHash("0x" + strings.Repeat("ff", 32))
+	if got := Add(max, big.NewInt(2)); got != common.BigToHash(big.NewInt(1)) {
+		t.Fatalf("Add wrapped to %s, want 0x..01", got.Hex())
+	}
+}
+
+func TestERC7201(t *testing.T) {
+	// Storage locations declared by OpenZeppelin Contracts Upgradeable v5.
+	tests := map[string]string{
+		"openzeppelin.storage.ERC20":         "0x52c63247e1f47db19d5ce0460030c497f067ca4cebf71ba98eeadabe20bace00",
+		"openzeppelin.storage.ERC721":        "0x80bb2b638cc20bc4d0a60d66940f3ab4a00c1d7b313497ca82fb0b4ab0079300",
+		"openzeppelin.storage.Ownable":       "0x9016d09d72d40fdae2fd8ceac6b6234c7706214fd39c1cd1e609a0528c199300",
+		"openzeppelin.storage.Pausable":      "0xcd5ed15c6e187e77e9aee88184c21f4f2182ab5827cb3b7e07fbedcd63f03300",
+		"openzeppelin.storage.Initializable": "0xf0c57e16840df040f15088dc2f81fe391c3923bec73e23a9662efc9c229c6a00",
+	}
+	for namespace, want := range tests {
+		if got := ERC7201(namespace); got != common.HexToHash(want) {
+			t.Errorf("ERC7201(%q) = %s, want %s", namespace, got.Hex(), want)
+		}
+		d, err := Parse("field(erc7201:" + namespace + ", 3)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Key != plus(common.HexToHash(want), 3) || len(d.Steps) != 2 {
+			t.Errorf("field(erc7201:%s, 3) = %s with %d steps", namespace, d.Key.Hex(), len(d.Steps))
+		}
+	}
+
+	if _, err := Parse("erc7201:"); err == nil {
+		t.Fatal("expected an error for a missing namespace")
+	}
+}
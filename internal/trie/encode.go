@@ -0,0 +1,64 @@
+package trie
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func NewRef(n Node) Ref {
+	if n == nil {
+		return Ref{}
+	}
+	raw := n.Encode()
+	if len(raw) >= common.HashLength {
+		return Ref{Hash: crypto.Keccak256(raw), Node: n}
+	}
+	return Ref{Node: n, Raw: raw}
+}
+
+func (b *BranchNode) Encode() []byte {
+	w := rlp.NewEncoderBuffer(nil)
+	list := w.List()
+	for _, child := range b.Children {
+		child.encode(w)
+	}
+	w.WriteBytes(b.Value)
+	w.ListEnd(list)
+	return w.ToBytes()
+}
+
+func (e *ExtensionNode) Encode() []byte {
+	w := rlp.NewEncoderBuffer(nil)
+	list := w.List()
+	w.WriteBytes(canonicalHP(e.SharedPath))
+	e.NextNode.encode(w)
+	w.ListEnd(list)
+	return w.ToBytes()
+}
+
+func (l *LeafNode) Encode() []byte {
+	w := rlp.NewEncoderBuffer(nil)
+	list := w.List()
+	w.WriteBytes(canonicalHP(l.PathEnd))
+	w.WriteBytes(l.Value)
+	w.ListEnd(list)
+	return w.ToBytes()
+}
+
+func (b *BranchNode) Hash() common.Hash { return crypto.Keccak256Hash(b.Encode()) }
+
+func (e *ExtensionNode) Hash() common.Hash { return crypto.Keccak256Hash(e.Encode()) }
+
+func (l *LeafNode) Hash() common.Hash { return crypto.Keccak256Hash(l.Encode()) }
+
+func (r Ref) encode(w rlp.EncoderBuffer) {
+	switch {
+	case r.Inline():
+		w.Write(r.Node.Encode())
+	case r.Empty():
+		w.WriteBytes(nil)
+	default:
+		w.WriteBytes(r.Hash)
+	}
+}
@@ -0,0 +1,111 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestHexPrefix(t *testing.T) {
+	// Vectors from Appendix C of the Ethereum yellow paper.
+	tests := []struct {
+		nibbles string
+		isLeaf  bool
+		encoded string
+	}{
+		{"", false, "00"},
+		{"", true, "20"},
+		{"12345", false, "112345"},
+		{"012345", false, "00012345"},
+		{"0f1cb8", true, "200f1cb8"},
+		{"f1cb8", true, "3f1cb8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoded, func(t *testing.T) {
+			encoded, err := EncodeHP(tt.nibbles, tt.isLeaf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(encoded); got != tt.encoded {
+				t.Fatalf("EncodeHP(%q, %v) = %s, want %s", tt.nibbles, tt.isLeaf, got, tt.encoded)
+			}
+			nibbles, isLeaf := DecodeHP(encoded)
+			if nibbles != tt.nibbles || isLeaf != tt.isLeaf {
+				t.Fatalf("DecodeHP(%s) = %q, %v, want %q, %v", tt.encoded, nibbles, isLeaf, tt.nibbles, tt.isLeaf)
+			}
+		})
+	}
+
+	for _, nibbles := range []string{"0g", "12 3", "x"} {
+		if encoded, err := EncodeHP(nibbles, true); err == nil {
+			t.Errorf("EncodeHP(%q) = %x, want an error", nibbles, encoded)
+		}
+	}
+}
+
+func TestNodeEncoding(t *testing.T) {
+	path := hexPrefix("abc", true)
+	value := bytes.Repeat([]byte{0x01}, 40)
+	leaf := &LeafNode{PathEnd: path, Value: value}
+
+	want, err := rlp.EncodeToBytes([][]byte{path, value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(leaf.Encode(), want) {
+		t.Fatalf("leaf encodes to %x, want %x", leaf.Encode(), want)
+	}
+	if leaf.Hash() != crypto.Keccak256Hash(want) {
+		t.Fatal("leaf hash is not the keccak of its encoding")
+	}
+
+	padded := &LeafNode{PathEnd: []byte{0x21, 0x02}, Value: value}
+	if got := padded.Encode()[2]; got != 0x20 {
+		t.Fatalf("padded path re-encodes with flag byte %#x, want 0x20", got)
+	}
+
+	var branch BranchNode
+	branch.Children[3] = NewRef(leaf)
+	branch.Value = []byte{0x07}
+	items := make([]interface{}, 17)
+	for i := range items {
+		items[i] = []byte{}
+	}
+	items[3] = leaf.Hash().Bytes()
+	items[16] = []byte{0x07}
+	want, err = rlp.EncodeToBytes(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(branch.Encode(), want) {
+		t.Fatalf("branch encodes to %x, want %x", branch.Encode(), want)
+	}
+}
+
+func TestNewRef(t *testing.T) {
+	small := &LeafNode{PathEnd: hexPrefix("1", true), Value: []byte{0x01}}
+	large := &LeafNode{PathEnd: hexPrefix("1", true), Value: bytes.Repeat([]byte{0x01}, 40)}
+
+	if ref := NewRef(nil); !ref.Empty() {
+		t.Fatal("nil node should give an empty reference")
+	}
+
+	ref := NewRef(small)
+	if !ref.Inline() || ref.Hash != nil {
+		t.Fatal("node shorter than 32 bytes should be referenced inline")
+	}
+	if !bytes.Equal(ref.Raw, small.Encode()) {
+		t.Fatalf("inline raw %x, want %x", ref.Raw, small.Encode())
+	}
+
+	ref = NewRef(large)
+	if ref.Inline() || ref.Empty() {
+		t.Fatal("node of 32 bytes or more should be referenced by hash")
+	}
+	if !bytes.Equal(ref.Hash, large.Hash().Bytes()) {
+		t.Fatalf("reference hash %x, want %s", ref.Hash, large.Hash().Hex())
+	}
+}
@@ -0,0 +1,55 @@
+package trie
+
+import "fmt"
+
+type ExclusionKind string
+
+const (
+	ExclusionEmptyTrie           ExclusionKind = "EmptyTrie"
+	ExclusionEmptyBranchSlot     ExclusionKind = "EmptyBranchSlot"
+	ExclusionEmptyBranchValue    ExclusionKind = "EmptyBranchValue"
+	ExclusionExtensionDivergence ExclusionKind = "ExtensionDivergence"
+	ExclusionLeafKeyMismatch     ExclusionKind = "LeafKeyMismatch"
+)
+
+type Exclusion struct {
+	Kind       ExclusionKind
+	Step       int
+	Depth      int
+	Nibble     int
+	NodePath   string
+	TargetPath string
+}
+
+func (e *Exclusion) DivergenceOffset() int {
+	n := len(e.NodePath)
+	if len(e.TargetPath) < n {
+		n = len(e.TargetPath)
+	}
+	for i := 0; i < n; i++ {
+		if e.NodePath[i] != e.TargetPath[i] {
+			return i
+		}
+	}
+	return n
+}
+
+func (e *Exclusion) String() string {
+	switch e.Kind {
+	case ExclusionEmptyTrie:
+		return "trie is empty (root is the empty trie hash)"
+	case ExclusionEmptyBranchSlot:
+		return fmt.Sprintf("empty slot at nibble '%x' (index %d) in Branch node at depth %d", e.Nibble, e.Nibble, e.Depth)
+	case ExclusionEmptyBranchValue:
+		return fmt.Sprintf("path ends at Branch node at depth %d which holds no value", e.Depth)
+	case ExclusionExtensionDivergence:
+		offset := e.DivergenceOffset()
+		return fmt.Sprintf("Extension shared path '%s' diverges from key path '%s' at nibble %d (depth %d)",
+			e.NodePath, e.TargetPath, offset, e.Depth+offset)
+	case ExclusionLeafKeyMismatch:
+		offset := e.DivergenceOffset()
+		return fmt.Sprintf("Leaf key suffix '%s' differs from key path '%s' at nibble %d (depth %d)",
+			e.NodePath, e.TargetPath, offset, e.Depth+offset)
+	}
+	return string(e.Kind)
+}
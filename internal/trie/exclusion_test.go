@@ -0,0 +1,77 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifyProofExclusion(t *testing.T) {
+	value := bytes.Repeat([]byte{0xaa}, 40)
+
+	// Keys 0x12 and 0x34 under a root branch.
+	leaf2 := mustRLP(t, [][]byte{{0x32}, value})
+	leaf4 := mustRLP(t, [][]byte{{0x34}, value})
+	children := make([][]byte, 17)
+	children[1] = crypto.Keccak256(leaf2)
+	children[3] = crypto.Keccak256(leaf4)
+	branch := mustRLP(t, children)
+
+	// Keys 0x0110 and 0x0120 under an extension with shared path "01".
+	leaf0 := mustRLP(t, [][]byte{{0x30}, value})
+	children = make([][]byte, 17)
+	children[1] = crypto.Keccak256(leaf0)
+	children[2] = crypto.Keccak256(leaf0)
+	extBranch := mustRLP(t, children)
+	extension := mustRLP(t, [][]byte{{0x00, 0x01}, crypto.Keccak256(extBranch)})
+
+	// A single leaf for key 0x0110.
+	single := mustRLP(t, [][]byte{{0x20, 0x01, 0x10}, value})
+
+	tests := []struct {
+		name       string
+		root       common.Hash
+		key        []byte
+		proof      [][]byte
+		kind       ExclusionKind
+		step       int
+		depth      int
+		nibble     int
+		nodePath   string
+		targetPath string
+		offset     int
+	}{
+		{"empty trie", types.EmptyRootHash, []byte{0x12}, nil, ExclusionEmptyTrie, 0, 0, -1, "", "", 0},
+		{"empty branch slot", crypto.Keccak256Hash(branch), []byte{0x22}, [][]byte{branch}, ExclusionEmptyBranchSlot, 0, 0, 2, "", "", 0},
+		{"empty branch value", crypto.Keccak256Hash(extension), []byte{0x01}, [][]byte{extension, extBranch}, ExclusionEmptyBranchValue, 1, 2, -1, "", "", 0},
+		{"extension divergence", crypto.Keccak256Hash(extension), []byte{0x02}, [][]byte{extension}, ExclusionExtensionDivergence, 0, 0, -1, "01", "02", 1},
+		{"leaf key mismatch", crypto.Keccak256Hash(single), []byte{0x01, 0x11}, [][]byte{single}, ExclusionLeafKeyMismatch, 0, 0, -1, "0110", "0111", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VerifyProof(tt.root, tt.key, tt.proof)
+			if err := result.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if result.Exists() {
+				t.Fatal("expected an exclusion proof")
+			}
+			e := result.Exclusion
+			if e == nil || e.Kind != tt.kind {
+				t.Fatalf("exclusion %+v, want %s", e, tt.kind)
+			}
+			if e.Step != tt.step || e.Depth != tt.depth || e.Nibble != tt.nibble {
+				t.Errorf("step %d depth %d nibble %d, want %d %d %d", e.Step, e.Depth, e.Nibble, tt.step, tt.depth, tt.nibble)
+			}
+			if e.NodePath != tt.nodePath || e.TargetPath != tt.targetPath || e.DivergenceOffset() != tt.offset {
+				t.Errorf("paths %q/%q at %d, want %q/%q at %d", e.NodePath, e.TargetPath, e.DivergenceOffset(), tt.nodePath, tt.targetPath, tt.offset)
+			}
+			if e.String() == string(e.Kind) {
+				t.Errorf("exclusion %s has no description", e.Kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package trie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ProofFragment rebuilds the part of the trie under root that is covered by
+// the given proof nodes. Nodes shared by several proofs appear once, and hash
+// references to nodes outside the proof stay unresolved (Ref.Node is nil).
+func ProofFragment(root common.Hash, proof [][]byte) (Node, error) {
+	nodes := make(map[common.Hash][]byte, len(proof))
+	for _, raw := range proof {
+		nodes[crypto.Keccak256Hash(raw)] = raw
+	}
+
+	raw, ok := nodes[root]
+	if !ok {
+		return nil, fmt.Errorf("root node %s is not in the proof", root.Hex())
+	}
+	return resolveFragment(raw, nodes)
+}
+
+func resolveFragment(raw []byte, nodes map[common.Hash][]byte) (Node, error) {
+	node, err := ParseNode(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := func(ref *Ref) error {
+		var childRaw []byte
+		switch {
+		case ref.Inline():
+			childRaw = ref.Raw
+		case !ref.Empty():
+			childRaw = nodes[common.BytesToHash(ref.Hash)]
+		}
+		if childRaw == nil {
+			return nil
+		}
+		child, err := resolveFragment(childRaw, nodes)
+		if err != nil {
+			return err
+		}
+		ref.Node = child
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *BranchNode:
+		for i := range n.Children {
+			if err := resolve(&n.Children[i]); err != nil {
+				return nil, err
+			}
+		}
+	case *ExtensionNode:
+		if err := resolve(&n.NextNode); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
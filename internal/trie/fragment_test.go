@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestProofFragment(t *testing.T) {
+	tr := NewTrie()
+	var keys [][]byte
+	for i := 0; i < 200; i++ {
+		key := crypto.Keccak256([]byte(fmt.Sprintf("key-%d", i)))
+		tr.Put(key, bytes.Repeat([]byte{byte(i + 1)}, 40))
+		keys = append(keys, key)
+	}
+	root := tr.Hash()
+
+	proven := keys[:3]
+	var proof [][]byte
+	for _, key := range proven {
+		proof = append(proof, tr.Prove(key)...)
+	}
+
+	fragment, err := ProofFragment(root, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fragment.Hash() != root {
+		t.Fatalf("fragment hash %s, want %s", fragment.Hash().Hex(), root.Hex())
+	}
+
+	partial := &Trie{root: fragment}
+	for _, key := range proven {
+		if !bytes.Equal(partial.Get(key), tr.Get(key)) {
+			t.Fatalf("key %x: fragment value %x, want %x", key, partial.Get(key), tr.Get(key))
+		}
+	}
+	if partial.Get(keys[100]) != nil {
+		t.Fatal("key outside the proofs should not resolve")
+	}
+}
+
+func TestProofFragmentErrors(t *testing.T) {
+	bad := []byte{0xc3, 0x01, 0x02, 0x03}
+	tests := []struct {
+		name  string
+		root  common.Hash
+		proof [][]byte
+		want  string
+	}{
+		{"missing root", common.HexToHash("0x01"), nil, "is not in the proof"},
+		{"invalid root node", crypto.Keccak256Hash(bad), [][]byte{bad}, "3 items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ProofFragment(tt.root, tt.proof)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
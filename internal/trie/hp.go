@@ -1,6 +1,9 @@
 package trie
 
-import "encoding/hex"
+import (
+	"encoding/hex"
+	"fmt"
+)
 
 func DecodeHP(path []byte) (string, bool) {
 	if len(path) == 0 {
@@ -17,3 +20,39 @@ func DecodeHP(path []byte) (string, bool) {
 		return hexPath[2:], isLeaf
 	}
 }
+
+// EncodeHP hex-prefix encodes a nibble string as a leaf or extension path.
+func EncodeHP(nibbles string, isLeaf bool) ([]byte, error) {
+	for i := 0; i < len(nibbles); i++ {
+		if nibbleIndex(nibbles[i]) < 0 {
+			return nil, fmt.Errorf("invalid nibble %q at offset %d", nibbles[i], i)
+		}
+	}
+	return hexPrefix(nibbles, isLeaf), nil
+}
+
+// hexPrefix is EncodeHP for nibble strings already known to be valid hex.
+func hexPrefix(nibbles string, isLeaf bool) []byte {
+	var flag byte
+	if isLeaf {
+		flag = 2
+	}
+	if len(nibbles)%2 == 1 {
+		flag++
+	} else {
+		nibbles = "0" + nibbles
+	}
+
+	path := make([]byte, 0, len(nibbles)/2+1)
+	path = append(path, flag<<4|byte(nibbleIndex(nibbles[0])))
+	for i := 1; i < len(nibbles); i += 2 {
+		path = append(path, byte(nibbleIndex(nibbles[i]))<<4|byte(nibbleIndex(nibbles[i+1])))
+	}
+	return path
+}
+
+// canonicalHP re-encodes a parsed hex-prefix path with zero padding.
+func canonicalHP(path []byte) []byte {
+	nibbles, isLeaf := DecodeHP(path)
+	return hexPrefix(nibbles, isLeaf)
+}
@@ -0,0 +1,271 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type Trie struct {
+	root Node
+}
+
+func NewTrie() *Trie {
+	return &Trie{}
+}
+
+func DeriveTrie(list types.DerivableList) *Trie {
+	t := NewTrie()
+	var valueBuf bytes.Buffer
+	for i := 0; i < list.Len(); i++ {
+		valueBuf.Reset()
+		list.EncodeIndex(i, &valueBuf)
+		t.Put(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(valueBuf.Bytes()))
+	}
+	return t
+}
+
+func (t *Trie) Root() Node {
+	return t.root
+}
+
+func (t *Trie) Hash() common.Hash {
+	if t.root == nil {
+		return types.EmptyRootHash
+	}
+	return t.root.Hash()
+}
+
+func (t *Trie) Get(key []byte) []byte {
+	n := t.root
+	path := hex.EncodeToString(key)
+	for n != nil {
+		switch cur := n.(type) {
+		case *BranchNode:
+			if path == "" {
+				return cur.Value
+			}
+			n = cur.Children[nibbleIndex(path[0])].Node
+			path = path[1:]
+		case *ExtensionNode:
+			sharedNibbles, _ := DecodeHP(cur.SharedPath)
+			if len(path) < len(sharedNibbles) || path[:len(sharedNibbles)] != sharedNibbles {
+				return nil
+			}
+			n = cur.NextNode.Node
+			path = path[len(sharedNibbles):]
+		case *LeafNode:
+			pathEnd, _ := DecodeHP(cur.PathEnd)
+			if path != pathEnd {
+				return nil
+			}
+			return cur.Value
+		}
+	}
+	return nil
+}
+
+func (t *Trie) Put(key, value []byte) {
+	if len(value) == 0 {
+		t.Delete(key)
+		return
+	}
+	t.root = insert(t.root, hex.EncodeToString(key), value)
+}
+
+func (t *Trie) Delete(key []byte) {
+	t.root = remove(t.root, hex.EncodeToString(key))
+}
+
+func (t *Trie) Prove(key []byte) [][]byte {
+	var proof [][]byte
+	n := t.root
+	hashed := true
+	path := hex.EncodeToString(key)
+	for n != nil {
+		if hashed {
+			proof = append(proof, n.Encode())
+		}
+
+		var next Ref
+		switch cur := n.(type) {
+		case *BranchNode:
+			if path == "" {
+				return proof
+			}
+			next = cur.Children[nibbleIndex(path[0])]
+			path = path[1:]
+		case *ExtensionNode:
+			sharedNibbles, _ := DecodeHP(cur.SharedPath)
+			if len(path) < len(sharedNibbles) || path[:len(sharedNibbles)] != sharedNibbles {
+				return proof
+			}
+			next = cur.NextNode
+			path = path[len(sharedNibbles):]
+		case *LeafNode:
+			return proof
+		}
+		n = next.Node
+		hashed = !next.Inline()
+	}
+	return proof
+}
+
+func newLeaf(path string, value []byte) *LeafNode {
+	return &LeafNode{PathEnd: hexPrefix(path, true), Value: value}
+}
+
+func newExtension(path string, next Ref) *ExtensionNode {
+	return &ExtensionNode{SharedPath: hexPrefix(path, false), NextNode: next}
+}
+
+func insert(n Node, path string, value []byte) Node {
+	switch cur := n.(type) {
+	case nil:
+		return newLeaf(path, value)
+
+	case *LeafNode:
+		pathEnd, _ := DecodeHP(cur.PathEnd)
+		if pathEnd == path {
+			return newLeaf(path, value)
+		}
+
+		c := commonPrefixLength(pathEnd, path)
+		branch := &BranchNode{}
+		if c == len(pathEnd) {
+			branch.Value = cur.Value
+		} else {
+			branch.Children[nibbleIndex(pathEnd[c])] = NewRef(newLeaf(pathEnd[c+1:], cur.Value))
+		}
+		if c == len(path) {
+			branch.Value = value
+		} else {
+			branch.Children[nibbleIndex(path[c])] = NewRef(newLeaf(path[c+1:], value))
+		}
+
+		if c > 0 {
+			return newExtension(path[:c], NewRef(branch))
+		}
+		return branch
+
+	case *ExtensionNode:
+		sharedNibbles, _ := DecodeHP(cur.SharedPath)
+		c := commonPrefixLength(sharedNibbles, path)
+		if c == len(sharedNibbles) {
+			child := insert(cur.NextNode.Node, path[c:], value)
+			return newExtension(sharedNibbles, NewRef(child))
+		}
+
+		branch := &BranchNode{}
+		if c+1 == len(sharedNibbles) {
+			branch.Children[nibbleIndex(sharedNibbles[c])] = cur.NextNode
+		} else {
+			branch.Children[nibbleIndex(sharedNibbles[c])] = NewRef(newExtension(sharedNibbles[c+1:], cur.NextNode))
+		}
+		if c == len(path) {
+			branch.Value = value
+		} else {
+			branch.Children[nibbleIndex(path[c])] = NewRef(newLeaf(path[c+1:], value))
+		}
+
+		if c > 0 {
+			return newExtension(path[:c], NewRef(branch))
+		}
+		return branch
+
+	case *BranchNode:
+		branch := *cur
+		if path == "" {
+			branch.Value = value
+			return &branch
+		}
+		index := nibbleIndex(path[0])
+		branch.Children[index] = NewRef(insert(cur.Children[index].Node, path[1:], value))
+		return &branch
+	}
+	return n
+}
+
+func remove(n Node, path string) Node {
+	switch cur := n.(type) {
+	case *LeafNode:
+		pathEnd, _ := DecodeHP(cur.PathEnd)
+		if pathEnd == path {
+			return nil
+		}
+		return n
+
+	case *ExtensionNode:
+		sharedNibbles, _ := DecodeHP(cur.SharedPath)
+		if len(path) < len(sharedNibbles) || path[:len(sharedNibbles)] != sharedNibbles {
+			return n
+		}
+		child := remove(cur.NextNode.Node, path[len(sharedNibbles):])
+		return collapseExtension(sharedNibbles, child)
+
+	case *BranchNode:
+		branch := *cur
+		if path == "" {
+			branch.Value = nil
+		} else {
+			index := nibbleIndex(path[0])
+			branch.Children[index] = NewRef(remove(cur.Children[index].Node, path[1:]))
+		}
+		return collapseBranch(&branch)
+	}
+	return n
+}
+
+func collapseExtension(sharedNibbles string, child Node) Node {
+	switch c := child.(type) {
+	case nil:
+		return nil
+	case *ExtensionNode:
+		childNibbles, _ := DecodeHP(c.SharedPath)
+		return newExtension(sharedNibbles+childNibbles, c.NextNode)
+	case *LeafNode:
+		pathEnd, _ := DecodeHP(c.PathEnd)
+		return newLeaf(sharedNibbles+pathEnd, c.Value)
+	}
+	return newExtension(sharedNibbles, NewRef(child))
+}
+
+func collapseBranch(b *BranchNode) Node {
+	only := -1
+	count := 0
+	for i, child := range b.Children {
+		if !child.Empty() {
+			only = i
+			count++
+		}
+	}
+	if len(b.Value) > 0 {
+		count++
+	}
+
+	switch {
+	case count == 0:
+		return nil
+	case count > 1:
+		return b
+	case only < 0:
+		return newLeaf("", b.Value)
+	}
+	return collapseExtension(string("0123456789abcdef"[only]), b.Children[only].Node)
+}
+
+func commonPrefixLength(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+)
+
+// rawList is a types.DerivableList whose items are already encoded.
+type rawList [][]byte
+
+func (l rawList) Len() int { return len(l) }
+
+func (l rawList) EncodeIndex(i int, w *bytes.Buffer) { w.Write(l[i]) }
+
+func newRawList(n, size int) rawList {
+	list := make(rawList, n)
+	for i := range list {
+		list[i] = bytes.Repeat([]byte{byte(i)}, size)
+		list[i][0] = byte(i >> 8)
+	}
+	return list
+}
+
+func TestDeriveTrieMatchesDeriveSha(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 16, 127, 128, 129, 300} {
+		for _, size := range []int{1, 4, 100} {
+			t.Run(fmt.Sprintf("%d items of %d bytes", n, size), func(t *testing.T) {
+				list := newRawList(n, size)
+				want := types.DeriveSha(list, gethtrie.NewStackTrie(nil))
+				if got := DeriveTrie(list).Hash(); got != want {
+					t.Fatalf("root %s, want %s", got.Hex(), want.Hex())
+				}
+			})
+		}
+	}
+}
+
+func TestTriePutDelete(t *testing.T) {
+	list := newRawList(150, 40)
+	want := DeriveTrie(list).Hash()
+
+	order := rand.New(rand.NewSource(1)).Perm(len(list))
+	tr := NewTrie()
+	for _, i := range order {
+		tr.Put(rlp.AppendUint64(nil, uint64(i)), list[i])
+	}
+	if tr.Hash() != want {
+		t.Fatalf("insertion order changed the root: %s, want %s", tr.Hash().Hex(), want.Hex())
+	}
+
+	extra := rlp.AppendUint64(nil, 1000)
+	tr.Put(extra, []byte("extra"))
+	if tr.Hash() == want {
+		t.Fatal("inserting a key did not change the root")
+	}
+	if !bytes.Equal(tr.Get(extra), []byte("extra")) {
+		t.Fatalf("Get returned %x", tr.Get(extra))
+	}
+	tr.Delete(extra)
+	if tr.Hash() != want {
+		t.Fatalf("deleting the key did not restore the root: %s, want %s", tr.Hash().Hex(), want.Hex())
+	}
+	if tr.Get(extra) != nil {
+		t.Fatal("deleted key is still present")
+	}
+
+	for _, i := range order {
+		key := rlp.AppendUint64(nil, uint64(i))
+		if !bytes.Equal(tr.Get(key), list[i]) {
+			t.Fatalf("item %d: Get returned %x", i, tr.Get(key))
+		}
+		result := VerifyProof(want, key, tr.Prove(key))
+		if err := result.Err(); err != nil {
+			t.Fatalf("item %d: %v", i, err)
+		}
+		if !bytes.Equal(result.Value, list[i]) {
+			t.Fatalf("item %d: proven value %x", i, result.Value)
+		}
+	}
+
+	for _, i := range order {
+		tr.Delete(rlp.AppendUint64(nil, uint64(i)))
+	}
+	if tr.Hash() != types.EmptyRootHash || tr.Root() != nil {
+		t.Fatalf("trie not empty after deleting every key: %s", tr.Hash().Hex())
+	}
+}
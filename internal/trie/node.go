@@ -1,19 +1,30 @@
 package trie
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
 
 type Node interface {
 	Type() string
+	Encode() []byte
+	Hash() common.Hash
+}
+
+type Ref struct {
+	Hash []byte
+	Node Node
+	Raw  []byte
 }
 
 type BranchNode struct {
-	Children [16][]byte
+	Children [16]Ref
 	Value    []byte
 }
 
 type ExtensionNode struct {
 	SharedPath []byte
-	NextNode   []byte
+	NextNode   Ref
 }
 
 type LeafNode struct {
@@ -21,18 +32,22 @@ type LeafNode struct {
 	Value   []byte
 }
 
-type RenderNode struct {
-	Key  []byte
-	Node Node
-}
-
 func (b *BranchNode) Type() string { return "Branch" }
 
 func (e *ExtensionNode) Type() string { return "Extension" }
 
 func (l *LeafNode) Type() string { return "Leaf" }
 
-type RenderNodeData struct {
-	Key  common.Hash
-	Node Node
+func (r Ref) Empty() bool { return r.Hash == nil && r.Node == nil }
+
+func (r Ref) Inline() bool { return r.Hash == nil && r.Node != nil }
+
+func (r Ref) String() string {
+	switch {
+	case r.Inline():
+		return "inline:" + hexutil.Encode(r.Raw)
+	case r.Empty():
+		return "empty"
+	}
+	return hexutil.Encode(r.Hash)
 }
@@ -3,31 +3,68 @@ package trie
 import (
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+type rlpItem struct {
+	kind    rlp.Kind
+	content []byte
+	raw     []byte
+}
+
 func ParseNode(rawData []byte) (Node, error) {
 	if len(rawData) == 0 {
 		return nil, fmt.Errorf("empty raw data")
 	}
 
-	var decodedList [][]byte
-	if err := rlp.DecodeBytes(rawData, &decodedList); err != nil {
+	elems, rest, err := rlp.SplitList(rawData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode RLP: %v", err)
 	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to decode RLP: %d trailing bytes after node", len(rest))
+	}
+
+	var items []rlpItem
+	for len(elems) > 0 {
+		kind, content, tail, err := rlp.Split(elems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode RLP item %d: %v", len(items), err)
+		}
+		items = append(items, rlpItem{
+			kind:    kind,
+			content: content,
+			raw:     elems[:len(elems)-len(tail)],
+		})
+		elems = tail
+	}
 
-	switch len(decodedList) {
+	switch len(items) {
 	case 17:
-		var children [16][]byte
-		copy(children[:], decodedList[:16])
+		var children [16]Ref
+		for i := 0; i < 16; i++ {
+			ref, err := parseRef(items[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid branch child %d: %v", i, err)
+			}
+			children[i] = ref
+		}
+
+		value, err := items[16].bytes()
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch value: %v", err)
+		}
 
 		return &BranchNode{
 			Children: children,
-			Value:    decodedList[16],
+			Value:    value,
 		}, nil
 	case 2:
-		pathBytes := decodedList[0]
-		valueOrHash := decodedList[1]
+		pathBytes, err := items[0].bytes()
+		if err != nil {
+			return nil, fmt.Errorf("invalid node path: %v", err)
+		}
 
 		if len(pathBytes) == 0 {
 			return nil, fmt.Errorf("node has 2 items but path is empty")
@@ -36,19 +73,59 @@ func ParseNode(rawData []byte) (Node, error) {
 		firstNibble := pathBytes[0] >> 4
 		switch firstNibble {
 		case 0, 1:
+			next, err := parseRef(items[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid extension child: %v", err)
+			}
+			if next.Empty() {
+				return nil, fmt.Errorf("extension node has empty child reference")
+			}
 			return &ExtensionNode{
 				SharedPath: pathBytes,
-				NextNode:   valueOrHash,
+				NextNode:   next,
 			}, nil
 		case 2, 3:
+			value, err := items[1].bytes()
+			if err != nil {
+				return nil, fmt.Errorf("invalid leaf value: %v", err)
+			}
 			return &LeafNode{
 				PathEnd: pathBytes,
-				Value:   valueOrHash,
+				Value:   value,
 			}, nil
 		default:
 			return nil, fmt.Errorf("invalid hex-prefix nibble: %x", firstNibble)
 		}
 	default:
-		return nil, fmt.Errorf("invalid node with %d items", len(decodedList))
+		return nil, fmt.Errorf("invalid node with %d items", len(items))
+	}
+}
+
+func parseRef(item rlpItem) (Ref, error) {
+	if item.kind == rlp.List {
+		if len(item.raw) >= common.HashLength {
+			return Ref{}, fmt.Errorf("embedded node is %d bytes, must be shorter than %d", len(item.raw), common.HashLength)
+		}
+		node, err := ParseNode(item.raw)
+		if err != nil {
+			return Ref{}, fmt.Errorf("invalid embedded node: %v", err)
+		}
+		return Ref{Node: node, Raw: item.raw}, nil
+	}
+
+	switch len(item.content) {
+	case 0:
+		return Ref{}, nil
+	case common.HashLength:
+		return Ref{Hash: item.content}, nil
+	default:
+		return Ref{}, fmt.Errorf("hash reference is %d bytes, expected %d", len(item.content), common.HashLength)
+	}
+}
+
+func (i rlpItem) bytes() ([]byte, error) {
+	if i.kind == rlp.List {
+		return nil, fmt.Errorf("expected byte string, got list")
 	}
+	return i.content, nil
 }
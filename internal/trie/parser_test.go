@@ -0,0 +1,127 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// inlineBranches builds a trie holding 0x01, 0x02 and 0x12 whose nodes below
+// the root are all small enough to be embedded in their parent.
+func inlineBranches(t *testing.T) (root []byte, inner []byte) {
+	t.Helper()
+	children := make([]interface{}, 17)
+	for i := range children {
+		children[i] = []byte{}
+	}
+	children[1] = rlp.RawValue(mustRLP(t, [][]byte{{0x20}, {0x01}}))
+	children[2] = rlp.RawValue(mustRLP(t, [][]byte{{0x20}, {0x02}}))
+	inner = mustRLP(t, children)
+
+	children = make([]interface{}, 17)
+	for i := range children {
+		children[i] = []byte{}
+	}
+	children[0] = rlp.RawValue(inner)
+	children[1] = rlp.RawValue(mustRLP(t, [][]byte{{0x32}, {0x03}}))
+	return mustRLP(t, children), inner
+}
+
+func TestParseNodeInlineChildren(t *testing.T) {
+	raw, inner := inlineBranches(t)
+	node, err := ParseNode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, ok := node.(*BranchNode)
+	if !ok {
+		t.Fatalf("root is %s, want Branch", node.Type())
+	}
+
+	tests := []struct {
+		index int
+		typ   string
+		size  int
+	}{
+		{0, "Branch", len(inner)},
+		{1, "Leaf", 3},
+	}
+	for _, tt := range tests {
+		child := branch.Children[tt.index]
+		if !child.Inline() || child.Hash != nil {
+			t.Fatalf("child %d is not inline", tt.index)
+		}
+		if child.Node.Type() != tt.typ || len(child.Raw) != tt.size {
+			t.Fatalf("child %d is a %d-byte %s, want a %d-byte %s", tt.index, len(child.Raw), child.Node.Type(), tt.size, tt.typ)
+		}
+	}
+	if !branch.Children[2].Empty() {
+		t.Fatal("child 2 should be empty")
+	}
+}
+
+func TestVerifyProofInlineSteps(t *testing.T) {
+	raw, _ := inlineBranches(t)
+	root := crypto.Keccak256Hash(raw)
+
+	result := VerifyProof(root, []byte{0x02}, [][]byte{raw})
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.Value, []byte{0x02}) {
+		t.Fatalf("value %x, want 02", result.Value)
+	}
+	if len(result.Steps) != 3 {
+		t.Fatalf("%d steps, want 3", len(result.Steps))
+	}
+	if result.Steps[0].Inline || result.Steps[0].Hash != root {
+		t.Fatal("root step must be hash-referenced")
+	}
+	for i, step := range result.Steps[1:] {
+		if !step.Inline {
+			t.Fatalf("step %d is not inline", i+1)
+		}
+		if step.Hash != (common.Hash{}) {
+			t.Fatalf("inline step %d has hash %s, want none", i+1, step.Hash.Hex())
+		}
+		if len(step.Raw) == 0 {
+			t.Fatalf("inline step %d has no raw encoding", i+1)
+		}
+	}
+}
+
+func TestParseNodeErrors(t *testing.T) {
+	leaf := []byte{0xc4, 0x82, 0x20, 0x01, 0x01}
+	oversized := make([]interface{}, 17)
+	for i := range oversized {
+		oversized[i] = []byte{}
+	}
+	oversized[0] = rlp.RawValue(mustRLP(t, [][]byte{{0x20}, bytes.Repeat([]byte{0x01}, 40)}))
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"empty", nil},
+		{"not a list", []byte{0x83, 0x01, 0x02, 0x03}},
+		{"trailing bytes", append(common.CopyBytes(leaf), 0x00)},
+		{"three items", []byte{0xc3, 0x01, 0x02, 0x03}},
+		{"bad hex-prefix flag", []byte{0xc4, 0x82, 0x40, 0x01, 0x01}},
+		{"empty path", []byte{0xc2, 0x80, 0x01}},
+		{"short hash reference", []byte{0xc4, 0x82, 0x00, 0x01, 0x01}},
+		{"oversized embedded node", mustRLP(t, oversized)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if node, err := ParseNode(tt.raw); err == nil {
+				t.Fatalf("expected an error, got %s node", node.Type())
+			}
+		})
+	}
+	if _, err := ParseNode(leaf); err != nil {
+		t.Fatalf("valid leaf rejected: %v", err)
+	}
+}
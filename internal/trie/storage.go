@@ -0,0 +1,22 @@
+package trie
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func DecodeStorageValue(raw []byte) (common.Hash, error) {
+	content, rest, err := rlp.SplitString(raw)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("storage value is not an RLP string: %w", err)
+	}
+	if len(rest) > 0 {
+		return common.Hash{}, fmt.Errorf("storage value has %d trailing bytes", len(rest))
+	}
+	if len(content) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("storage value is %d bytes, longer than 32", len(content))
+	}
+	return common.BytesToHash(content), nil
+}
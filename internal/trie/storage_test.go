@@ -0,0 +1,44 @@
+package trie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDecodeStorageValue(t *testing.T) {
+	full := bytes.Repeat([]byte{0xff}, 32)
+	tests := []struct {
+		name string
+		raw  []byte
+		want common.Hash
+		err  string
+	}{
+		{"single byte", []byte{0x2a}, common.BytesToHash([]byte{0x2a}), ""},
+		{"short string", []byte{0x82, 0x01, 0x00}, common.BytesToHash([]byte{0x01, 0x00}), ""},
+		{"full word", append([]byte{0xa0}, full...), common.BytesToHash(full), ""},
+		{"too long", append([]byte{0xa1}, append(full, 0x01)...), common.Hash{}, "longer than 32"},
+		{"trailing bytes", []byte{0x01, 0x02}, common.Hash{}, "1 trailing bytes"},
+		{"list", []byte{0xc1, 0x01}, common.Hash{}, "not an RLP string"},
+		{"empty", nil, common.Hash{}, "not an RLP string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeStorageValue(tt.raw)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("value %s, want %s", got.Hex(), tt.want.Hex())
+			}
+		})
+	}
+}
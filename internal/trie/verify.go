@@ -0,0 +1,203 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VerificationStep is one node on the proof path. Hash is left empty for
+// inline steps, which are embedded in their parent and have no hash.
+type VerificationStep struct {
+	Hash       common.Hash
+	Raw        []byte
+	Node       Node
+	Inline     bool
+	Depth      int
+	Consumed   string
+	ChildIndex int
+	ChildRef   Ref
+}
+
+type VerificationResult struct {
+	Root       common.Hash
+	Key        []byte
+	Path       string
+	Steps      []VerificationStep
+	Value      []byte
+	Exclusion  *Exclusion
+	Verified   bool
+	FailedStep int
+	Reason     string
+}
+
+func (r *VerificationResult) Exists() bool {
+	return r.Verified && len(r.Value) > 0
+}
+
+func (r *VerificationResult) Err() error {
+	if r.Verified {
+		return nil
+	}
+	return fmt.Errorf("step %d: %s", r.FailedStep, r.Reason)
+}
+
+func (r *VerificationResult) exclude(step VerificationStep, exclusion Exclusion) *VerificationResult {
+	exclusion.Step = len(r.Steps)
+	exclusion.Depth = step.Depth
+	r.Exclusion = &exclusion
+	return r.finish(step, nil)
+}
+
+func (r *VerificationResult) fail(step *VerificationStep, reason string) *VerificationResult {
+	r.FailedStep = len(r.Steps)
+	if step != nil {
+		r.Steps = append(r.Steps, *step)
+	}
+	r.Reason = reason
+	return r
+}
+
+func (r *VerificationResult) finish(step VerificationStep, value []byte) *VerificationResult {
+	r.Steps = append(r.Steps, step)
+	r.Value = value
+	r.Verified = true
+	return r
+}
+
+func VerifyProof(root common.Hash, key []byte, proof [][]byte) *VerificationResult {
+	result := &VerificationResult{
+		Root:       root,
+		Key:        key,
+		Path:       hex.EncodeToString(key),
+		FailedStep: -1,
+	}
+
+	nodes := make(map[common.Hash][]byte, len(proof))
+	for _, raw := range proof {
+		nodes[crypto.Keccak256Hash(raw)] = raw
+	}
+
+	if root == types.EmptyRootHash && len(nodes[root]) == 0 {
+		result.Verified = true
+		result.Exclusion = &Exclusion{Kind: ExclusionEmptyTrie, Nibble: -1}
+		return result
+	}
+
+	wantHash := root
+	var inlineRef Ref
+	remaining := result.Path
+
+	for {
+		step := VerificationStep{
+			Inline:     inlineRef.Inline(),
+			Depth:      len(result.Path) - len(remaining),
+			ChildIndex: -1,
+		}
+
+		if step.Inline {
+			step.Raw = inlineRef.Raw
+			step.Node = inlineRef.Node
+		} else {
+			raw, ok := nodes[wantHash]
+			if !ok {
+				return result.fail(nil, fmt.Sprintf("proof node %s missing", wantHash.Hex()))
+			}
+			step.Hash = wantHash
+			step.Raw = raw
+
+			node, err := ParseNode(raw)
+			if err != nil {
+				return result.fail(nil, fmt.Sprintf("bad proof node %s: %v", step.Hash.Hex(), err))
+			}
+			step.Node = node
+			if !bytes.Equal(node.Encode(), raw) {
+				return result.fail(&step, "proof node is not canonically encoded")
+			}
+		}
+
+		var ref Ref
+		switch n := step.Node.(type) {
+		case *BranchNode:
+			if len(remaining) == 0 {
+				if len(n.Value) == 0 {
+					return result.exclude(step, Exclusion{Kind: ExclusionEmptyBranchValue, Nibble: -1})
+				}
+				return result.finish(step, n.Value)
+			}
+
+			index := nibbleIndex(remaining[0])
+			if index < 0 {
+				return result.fail(&step, fmt.Sprintf("invalid path nibble '%c'", remaining[0]))
+			}
+			step.ChildIndex = index
+			step.Consumed = remaining[:1]
+
+			ref = n.Children[index]
+			if ref.Empty() {
+				return result.exclude(step, Exclusion{Kind: ExclusionEmptyBranchSlot, Nibble: index})
+			}
+			remaining = remaining[1:]
+
+		case *ExtensionNode:
+			sharedNibbles, _ := DecodeHP(n.SharedPath)
+			if !strings.HasPrefix(remaining, sharedNibbles) {
+				return result.exclude(step, Exclusion{
+					Kind:       ExclusionExtensionDivergence,
+					Nibble:     -1,
+					NodePath:   sharedNibbles,
+					TargetPath: remaining,
+				})
+			}
+			step.Consumed = sharedNibbles
+
+			ref = n.NextNode
+			if ref.Empty() {
+				return result.fail(&step, "extension node has empty child reference")
+			}
+			remaining = remaining[len(sharedNibbles):]
+
+		case *LeafNode:
+			pathEnd, _ := DecodeHP(n.PathEnd)
+			if remaining != pathEnd {
+				return result.exclude(step, Exclusion{
+					Kind:       ExclusionLeafKeyMismatch,
+					Nibble:     -1,
+					NodePath:   pathEnd,
+					TargetPath: remaining,
+				})
+			}
+			step.Consumed = pathEnd
+			if len(n.Value) == 0 {
+				return result.fail(&step, "leaf node has empty value")
+			}
+			return result.finish(step, n.Value)
+		}
+
+		step.ChildRef = ref
+		result.Steps = append(result.Steps, step)
+
+		inlineRef = ref
+		if !ref.Inline() {
+			inlineRef = Ref{}
+			wantHash = common.BytesToHash(ref.Hash)
+		}
+	}
+}
+
+func nibbleIndex(nibbleChar byte) int {
+	switch {
+	case nibbleChar >= '0' && nibbleChar <= '9':
+		return int(nibbleChar - '0')
+	case nibbleChar >= 'a' && nibbleChar <= 'f':
+		return int(nibbleChar-'a') + 10
+	case nibbleChar >= 'A' && nibbleChar <= 'F':
+		return int(nibbleChar-'A') + 10
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package trie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func mustRLP(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	raw, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestVerifyProof(t *testing.T) {
+	value1 := bytes.Repeat([]byte{0x11}, 40)
+	value2 := bytes.Repeat([]byte{0x22}, 40)
+	leaf1 := mustRLP(t, [][]byte{{0x32}, value1})
+	leaf2 := mustRLP(t, [][]byte{{0x34}, value2})
+	children := make([][]byte, 17)
+	children[1] = crypto.Keccak256(leaf1)
+	children[3] = crypto.Keccak256(leaf2)
+	branch := mustRLP(t, children)
+	root := crypto.Keccak256Hash(branch)
+	tampered := mustRLP(t, [][]byte{{0x32}, value2})
+
+	tests := []struct {
+		name       string
+		root       common.Hash
+		key        []byte
+		proof      [][]byte
+		verified   bool
+		value      []byte
+		steps      int
+		failedStep int
+		reason     string
+	}{
+		{"leaf under nibble 1", root, []byte{0x12}, [][]byte{branch, leaf1}, true, value1, 2, -1, ""},
+		{"leaf under nibble 3", root, []byte{0x34}, [][]byte{leaf2, branch}, true, value2, 2, -1, ""},
+		{"absent key", root, []byte{0x22}, [][]byte{branch}, true, nil, 1, -1, ""},
+		{"empty trie", types.EmptyRootHash, []byte{0x12}, nil, true, nil, 0, -1, ""},
+		{"wrong root", common.HexToHash("0x01"), []byte{0x12}, [][]byte{branch, leaf1}, false, nil, 0, 0, "missing"},
+		{"missing leaf", root, []byte{0x12}, [][]byte{branch}, false, nil, 1, 1, "missing"},
+		{"tampered leaf", root, []byte{0x12}, [][]byte{branch, tampered}, false, nil, 1, 1, "missing"},
+		{"undecodable node", crypto.Keccak256Hash([]byte{0xc1, 0x80}), []byte{0x12}, [][]byte{{0xc1, 0x80}}, false, nil, 0, 0, "bad proof node"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VerifyProof(tt.root, tt.key, tt.proof)
+			if result.Verified != tt.verified {
+				t.Fatalf("verified %v, want %v (%v)", result.Verified, tt.verified, result.Err())
+			}
+			if !bytes.Equal(result.Value, tt.value) || result.Exists() != (tt.value != nil) {
+				t.Fatalf("value %x, want %x", result.Value, tt.value)
+			}
+			if len(result.Steps) != tt.steps {
+				t.Fatalf("%d steps, want %d", len(result.Steps), tt.steps)
+			}
+			if result.FailedStep != tt.failedStep || !strings.Contains(result.Reason, tt.reason) {
+				t.Fatalf("failed at %d with %q, want %d with %q", result.FailedStep, result.Reason, tt.failedStep, tt.reason)
+			}
+			if tt.steps > 0 && result.Steps[0].Hash != tt.root {
+				t.Fatalf("first step %s is not the root", result.Steps[0].Hash.Hex())
+			}
+		})
+	}
+}
+
+func TestVerifyProofNonCanonicalNode(t *testing.T) {
+	// An even-length leaf path must pad its flag nibble with zero; 0x21
+	// decodes to the same nibbles as 0x20 but is not the canonical encoding.
+	value := bytes.Repeat([]byte{0x11}, 40)
+	leaf := mustRLP(t, [][]byte{{0x21, 0x02}, value})
+	if _, err := ParseNode(leaf); err != nil {
+		t.Fatalf("non-canonical leaf should still decode: %v", err)
+	}
+
+	result := VerifyProof(crypto.Keccak256Hash(leaf), []byte{0x02}, [][]byte{leaf})
+	if result.Verified {
+		t.Fatal("non-canonical proof node verified")
+	}
+	if result.FailedStep != 0 || result.Reason != "proof node is not canonically encoded" {
+		t.Fatalf("failed at %d with %q", result.FailedStep, result.Reason)
+	}
+
+	canonical := mustRLP(t, [][]byte{{0x20, 0x02}, value})
+	if err := VerifyProof(crypto.Keccak256Hash(canonical), []byte{0x02}, [][]byte{canonical}).Err(); err != nil {
+		t.Fatalf("canonical leaf rejected: %v", err)
+	}
+}
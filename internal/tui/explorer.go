@@ -0,0 +1,468 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+type View int
+
+const (
+	ViewDecoded View = iota
+	ViewHash
+	ViewRLP
+)
+
+func (v View) String() string {
+	switch v {
+	case ViewHash:
+		return "hash"
+	case ViewRLP:
+		return "rlp"
+	}
+	return "decoded"
+}
+
+type Frame struct {
+	Title  string
+	Root   common.Hash
+	Key    string
+	Decode func(value []byte) []string
+
+	nodes  map[common.Hash][]byte
+	path   []entry
+	cursor int
+}
+
+type entry struct {
+	node   trie.Node
+	hash   common.Hash
+	raw    []byte
+	inline bool
+	via    string
+	prefix string
+}
+
+func (en entry) reference() string {
+	if en.inline {
+		return "inline (embedded in parent)"
+	}
+	return en.hash.Hex()
+}
+
+func NewProofFrame(title string, result *trie.VerificationResult, proof [][]byte) *Frame {
+	f := &Frame{
+		Title: title,
+		Root:  result.Root,
+		Key:   result.Path,
+		nodes: make(map[common.Hash][]byte, len(proof)),
+	}
+	for _, raw := range proof {
+		f.nodes[crypto.Keccak256Hash(raw)] = raw
+	}
+
+	for i, step := range result.Steps {
+		e := entry{
+			node:   step.Node,
+			hash:   step.Hash,
+			raw:    step.Raw,
+			inline: step.Inline,
+			prefix: result.Path[:step.Depth],
+		}
+		if i > 0 {
+			e.via = result.Steps[i-1].Consumed
+		}
+		f.path = append(f.path, e)
+	}
+	return f
+}
+
+func NewTrieFrame(title string, root trie.Node) *Frame {
+	f := &Frame{Title: title}
+	if root != nil {
+		f.Root = root.Hash()
+		f.path = []entry{{node: root, hash: f.Root, raw: root.Encode()}}
+	}
+	return f
+}
+
+func (f *Frame) resolve(ref trie.Ref) (entry, error) {
+	switch {
+	case ref.Empty():
+		return entry{}, fmt.Errorf("slot is empty")
+	case ref.Inline():
+		return entry{node: ref.Node, raw: ref.Raw, inline: true}, nil
+	case ref.Node != nil:
+		return entry{node: ref.Node, hash: common.BytesToHash(ref.Hash), raw: ref.Node.Encode()}, nil
+	}
+
+	hash := common.BytesToHash(ref.Hash)
+	raw, ok := f.nodes[hash]
+	if !ok {
+		return entry{}, fmt.Errorf("node %s is not part of the proof", hash.Hex())
+	}
+	node, err := trie.ParseNode(raw)
+	if err != nil {
+		return entry{}, fmt.Errorf("bad node %s: %w", hash.Hex(), err)
+	}
+	return entry{node: node, hash: hash, raw: raw}, nil
+}
+
+func (f *Frame) descend(ref trie.Ref, via string) error {
+	child, err := f.resolve(ref)
+	if err != nil {
+		return err
+	}
+	child.via = via
+	child.prefix = f.path[f.cursor].prefix + via
+	f.path = append(f.path[:f.cursor+1], child)
+	f.cursor++
+	return nil
+}
+
+func (e entry) leafKey() string {
+	leaf, ok := e.node.(*trie.LeafNode)
+	if !ok {
+		return ""
+	}
+	pathEnd, _ := trie.DecodeHP(leaf.PathEnd)
+	return e.prefix + pathEnd
+}
+
+type Explorer struct {
+	OpenStorage func(leafKey string, slot string) (*Frame, error)
+
+	frames   []*Frame
+	view     View
+	slotsOn  bool
+	slot     int
+	status   string
+	prompt   string
+	input    string
+	quitting bool
+}
+
+func NewExplorer(frame *Frame) *Explorer {
+	return &Explorer{frames: []*Frame{frame}}
+}
+
+func (e *Explorer) Push(frame *Frame) {
+	e.frames = append(e.frames, frame)
+}
+
+func (e *Explorer) Run() error {
+	t, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer t.close()
+
+	for !e.quitting {
+		width, height := t.size()
+		t.draw(e.render(width, height), width)
+
+		key, err := t.readKey()
+		if err != nil {
+			return err
+		}
+		if e.prompt != "" {
+			e.handlePrompt(key)
+		} else {
+			e.handleKey(key)
+		}
+	}
+	return nil
+}
+
+func (e *Explorer) frame() *Frame {
+	return e.frames[len(e.frames)-1]
+}
+
+func (e *Explorer) handleKey(key string) {
+	f := e.frame()
+	e.status = ""
+
+	switch key {
+	case "q", "ctrl-c":
+		e.quitting = true
+		return
+	case "v":
+		e.view = (e.view + 1) % 3
+		return
+	case "b", "backspace":
+		if len(e.frames) > 1 {
+			e.frames = e.frames[:len(e.frames)-1]
+			e.slotsOn = false
+		} else {
+			e.status = "already at the top-level trie"
+		}
+		return
+	}
+
+	if len(f.path) == 0 {
+		return
+	}
+	current := f.path[f.cursor]
+
+	if e.slotsOn {
+		branch := current.node.(*trie.BranchNode)
+		switch key {
+		case "up", "k":
+			e.slot = (e.slot + 15) % 16
+		case "down", "j":
+			e.slot = (e.slot + 1) % 16
+		case "enter", "right", "l":
+			if err := f.descend(branch.Children[e.slot], fmt.Sprintf("%x", e.slot)); err != nil {
+				e.status = err.Error()
+				return
+			}
+			e.slotsOn = false
+		case "esc", "left", "h":
+			e.slotsOn = false
+		}
+		return
+	}
+
+	switch key {
+	case "up", "k":
+		if f.cursor > 0 {
+			f.cursor--
+		}
+	case "down", "j":
+		if f.cursor < len(f.path)-1 {
+			f.cursor++
+		}
+	case "enter", "right", "l":
+		switch n := current.node.(type) {
+		case *trie.BranchNode:
+			e.slotsOn = true
+			e.slot = 0
+			if f.cursor+1 < len(f.path) {
+				e.slot = int(trieNibble(f.path[f.cursor+1].via))
+			}
+		case *trie.ExtensionNode:
+			shared, _ := trie.DecodeHP(n.SharedPath)
+			if err := f.descend(n.NextNode, shared); err != nil {
+				e.status = err.Error()
+			}
+		case *trie.LeafNode:
+			e.status = "leaf nodes have no children"
+		}
+	case "s":
+		if e.OpenStorage == nil {
+			e.status = "this trie has no storage tries"
+			return
+		}
+		if current.leafKey() == "" {
+			e.status = "move to an account leaf to open its storage trie"
+			return
+		}
+		e.prompt = "storage slot: "
+		e.input = ""
+	}
+}
+
+func (e *Explorer) handlePrompt(key string) {
+	switch key {
+	case "esc", "ctrl-c":
+		e.prompt = ""
+	case "backspace":
+		if len(e.input) > 0 {
+			e.input = e.input[:len(e.input)-1]
+		}
+	case "enter":
+		e.prompt = ""
+		f := e.frame()
+		frame, err := e.OpenStorage(f.path[f.cursor].leafKey(), e.input)
+		if err != nil {
+			e.status = err.Error()
+			return
+		}
+		e.frames = append(e.frames, frame)
+		e.slotsOn = false
+	default:
+		if len(key) == 1 {
+			e.input += key
+		}
+	}
+}
+
+func (e *Explorer) render(width, height int) []string {
+	f := e.frame()
+
+	titles := make([]string, len(e.frames))
+	for i, frame := range e.frames {
+		titles[i] = frame.Title
+	}
+
+	header := []string{
+		fmt.Sprintf("gethtried explore | %s | view: %s", strings.Join(titles, " > "), e.view),
+		fmt.Sprintf("Root: %s", f.Root.Hex()),
+	}
+	if f.Key != "" {
+		header = append(header, fmt.Sprintf("Key:  %s", f.Key))
+	}
+	header = append(header, "")
+
+	var body []string
+	focus := 0
+	if len(f.path) == 0 {
+		body = append(body, "(empty trie)")
+	}
+	for i, en := range f.path {
+		marker := "  "
+		if i == f.cursor {
+			marker = "> "
+			focus = len(body)
+		}
+		via := ""
+		if en.via != "" {
+			via = fmt.Sprintf("-[%s]-> ", en.via)
+		}
+		body = append(body, fmt.Sprintf("%s%2d %s%s %s", marker, i, via, en.node.Type(), en.reference()))
+	}
+
+	if len(f.path) > 0 {
+		body = append(body, "", strings.Repeat("-", width))
+		detail, detailFocus := e.renderDetail(f.path[f.cursor], width)
+		if e.slotsOn {
+			focus = len(body) + detailFocus
+		}
+		body = append(body, detail...)
+	}
+
+	footer := []string{""}
+	switch {
+	case e.prompt != "":
+		footer = append(footer, e.prompt+e.input+"_")
+	case e.status != "":
+		footer = append(footer, e.status)
+	default:
+		footer = append(footer, "")
+	}
+	help := "j/k move  enter open  v view  s storage  b back  q quit"
+	if e.slotsOn {
+		help = "j/k select slot  enter descend  esc close  v view  q quit"
+	}
+	footer = append(footer, help)
+
+	available := height - len(header) - len(footer)
+	if available < 1 {
+		available = 1
+	}
+	offset := 0
+	if focus >= available {
+		offset = focus - available + 1
+	}
+	end := offset + available
+	if end > len(body) {
+		end = len(body)
+	}
+
+	lines := append(header, body[offset:end]...)
+	for len(lines) < height-len(footer) {
+		lines = append(lines, "")
+	}
+	return append(lines, footer...)
+}
+
+func (e *Explorer) renderDetail(en entry, width int) ([]string, int) {
+	f := e.frame()
+	lines := []string{
+		fmt.Sprintf("%s node, %d bytes", en.node.Type(), len(en.raw)),
+		fmt.Sprintf("Hash:   %s", en.reference()),
+		fmt.Sprintf("Prefix: %s", en.prefix),
+	}
+	focus := 0
+
+	switch e.view {
+	case ViewRLP:
+		lines = append(lines, "", "RLP:")
+		lines = append(lines, wrap(hexutil.Encode(en.raw), width)...)
+	case ViewHash:
+		lines = append(lines, "", "Child references:")
+	}
+
+	switch n := en.node.(type) {
+	case *trie.BranchNode:
+		occupied := 0
+		for _, child := range n.Children {
+			if !child.Empty() {
+				occupied++
+			}
+		}
+		if e.view != ViewRLP {
+			lines = append(lines, fmt.Sprintf("Occupied slots: %d/16, value: %t", occupied, len(n.Value) > 0))
+		}
+		if e.slotsOn || e.view == ViewHash {
+			for i, child := range n.Children {
+				marker := "  "
+				if e.slotsOn && i == e.slot {
+					marker = "> "
+					focus = len(lines)
+				}
+				lines = append(lines, fmt.Sprintf("%s[%x] %s", marker, i, e.slotText(f, child)))
+			}
+		}
+		if len(n.Value) > 0 && e.view == ViewDecoded {
+			lines = append(lines, "Value: "+hexutil.Encode(n.Value))
+			if f.Decode != nil {
+				lines = append(lines, f.Decode(n.Value)...)
+			}
+		}
+	case *trie.ExtensionNode:
+		shared, _ := trie.DecodeHP(n.SharedPath)
+		switch e.view {
+		case ViewDecoded:
+			lines = append(lines, fmt.Sprintf("Shared path: '%s'", shared))
+			lines = append(lines, "Next:        "+e.slotText(f, n.NextNode))
+		case ViewHash:
+			lines = append(lines, "  [next] "+e.slotText(f, n.NextNode))
+		}
+	case *trie.LeafNode:
+		if e.view != ViewDecoded {
+			break
+		}
+		pathEnd, _ := trie.DecodeHP(n.PathEnd)
+		lines = append(lines, fmt.Sprintf("Key end:  '%s'", pathEnd))
+		lines = append(lines, "Full key: "+en.leafKey())
+		lines = append(lines, "Value:    "+hexutil.Encode(n.Value))
+		if f.Decode != nil {
+			lines = append(lines, f.Decode(n.Value)...)
+		}
+	}
+	return lines, focus
+}
+
+func (e *Explorer) slotText(f *Frame, ref trie.Ref) string {
+	switch {
+	case ref.Empty():
+		return "empty"
+	case ref.Inline():
+		return fmt.Sprintf("inline %s %s", ref.Node.Type(), hexutil.Encode(ref.Raw))
+	}
+	hash := common.BytesToHash(ref.Hash)
+	if _, ok := f.nodes[hash]; ok || ref.Node != nil {
+		return hash.Hex()
+	}
+	return hash.Hex() + " (not in proof)"
+}
+
+func trieNibble(via string) byte {
+	if via == "" {
+		return 0
+	}
+	c := via[0]
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	}
+	return 0
+}
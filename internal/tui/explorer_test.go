@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inchori/gethtried/internal/trie"
+)
+
+func TestExplorer(t *testing.T) {
+	tr := trie.NewTrie()
+	tr.Put([]byte{0x01}, []byte{0x01})
+	tr.Put([]byte{0x12}, make([]byte, 40))
+	root := tr.Hash().Hex()
+
+	proofFrame := func(key []byte) func() *Frame {
+		return func() *Frame {
+			proof := tr.Prove(key)
+			return NewProofFrame("State", trie.VerifyProof(tr.Hash(), key, proof), proof)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		frame func() *Frame
+		keys  []string
+		want  []string
+	}{
+		{
+			name:  "proof path",
+			frame: proofFrame([]byte{0x12}),
+			want:  []string{"view: decoded", "Root: " + root, "Key:  12", ">  0 Branch " + root, " 1 -[1]-> Leaf 0x"},
+		},
+		{
+			name:  "descend into an inline child",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"enter", "k", "enter"},
+			want:  []string{">  1 -[0]-> Leaf inline (embedded in parent)", "Hash:   inline (embedded in parent)", "Full key: 01"},
+		},
+		{
+			name:  "inline step in the proof",
+			frame: proofFrame([]byte{0x01}),
+			want:  []string{" 1 -[0]-> Leaf inline (embedded in parent)"},
+		},
+		{
+			name:  "hash reference outside the proof",
+			frame: proofFrame([]byte{0x01}),
+			keys:  []string{"enter", "j", "enter"},
+			want:  []string{"is not part of the proof"},
+		},
+		{
+			name:  "empty slot",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"enter", "j", "enter"},
+			want:  []string{"slot is empty"},
+		},
+		{
+			name:  "leaf has no children",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"j", "enter"},
+			want:  []string{"leaf nodes have no children"},
+		},
+		{
+			name:  "rlp view",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"v", "v"},
+			want:  []string{"view: rlp", "RLP:"},
+		},
+		{
+			name:  "no storage tries",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"s"},
+			want:  []string{"this trie has no storage tries"},
+		},
+		{
+			name:  "back at the top level",
+			frame: proofFrame([]byte{0x12}),
+			keys:  []string{"b"},
+			want:  []string{"already at the top-level trie"},
+		},
+		{
+			name:  "local trie",
+			frame: func() *Frame { return NewTrieFrame("Transactions", tr.Root()) },
+			keys:  []string{"enter", "enter"},
+			want:  []string{">  1 -[0]-> Leaf inline (embedded in parent)"},
+		},
+		{
+			name:  "empty trie",
+			frame: func() *Frame { return NewTrieFrame("Transactions", nil) },
+			keys:  []string{"j", "enter"},
+			want:  []string{"(empty trie)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExplorer(tt.frame())
+			for _, key := range tt.keys {
+				e.handleKey(key)
+			}
+			out := strings.Join(e.render(120, 60), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("screen does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestExplorerOpenStorage(t *testing.T) {
+	tr := trie.NewTrie()
+	tr.Put([]byte{0xab}, make([]byte, 40))
+	proof := tr.Prove([]byte{0xab})
+	e := NewExplorer(NewProofFrame("State", trie.VerifyProof(tr.Hash(), []byte{0xab}, proof), proof))
+
+	var gotKey, gotSlot string
+	e.OpenStorage = func(leafKey, slot string) (*Frame, error) {
+		gotKey, gotSlot = leafKey, slot
+		return NewTrieFrame("Storage", nil), nil
+	}
+	for _, key := range []string{"s", "1", "2", "backspace", "3", "enter"} {
+		if e.prompt != "" {
+			e.handlePrompt(key)
+		} else {
+			e.handleKey(key)
+		}
+	}
+	if gotKey != "ab" || gotSlot != "13" {
+		t.Fatalf("OpenStorage(%q, %q), want (ab, 13)", gotKey, gotSlot)
+	}
+	if out := strings.Join(e.render(80, 20), "\n"); !strings.Contains(out, "State > Storage") {
+		t.Fatalf("storage frame not pushed:\n%s", out)
+	}
+
+	e.handleKey("b")
+	e.handleKey("q")
+	if len(e.frames) != 1 || !e.quitting {
+		t.Fatalf("%d frames after going back, quitting %v", len(e.frames), e.quitting)
+	}
+}
+
+func TestTruncateAndWrap(t *testing.T) {
+	tests := []struct {
+		s        string
+		width    int
+		truncate string
+		wrap     []string
+	}{
+		{"abcdef", 10, "abcdef", []string{"abcdef"}},
+		{"abcdef", 4, "abc…", []string{"abcd", "ef"}},
+		{"abcdef", 3, "ab…", []string{"abc", "def"}},
+		{"äöü", 2, "ä…", []string{"ä", "ö", "ü"}},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.truncate {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.truncate)
+		}
+		if got := wrap(tt.s, tt.width); strings.Join(got, "|") != strings.Join(tt.wrap, "|") {
+			t.Errorf("wrap(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.wrap)
+		}
+	}
+}
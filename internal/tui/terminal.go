@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+type terminal struct {
+	in    *os.File
+	out   *bufio.Writer
+	keys  chan byte
+	state *term.State
+}
+
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("explore requires an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+
+	t := &terminal{in: os.Stdin, out: bufio.NewWriter(os.Stdout), keys: make(chan byte, 64), state: state}
+	go t.readInput()
+
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	t.out.Flush()
+	return t, nil
+}
+
+func (t *terminal) close() {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	_ = term.Restore(int(t.in.Fd()), t.state)
+}
+
+func (t *terminal) readInput() {
+	reader := bufio.NewReader(t.in)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			close(t.keys)
+			return
+		}
+		t.keys <- b
+	}
+}
+
+func (t *terminal) size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+func (t *terminal) readKey() (string, error) {
+	b, ok := <-t.keys
+	if !ok {
+		return "", fmt.Errorf("terminal input closed")
+	}
+
+	switch b {
+	case '\r', '\n':
+		return "enter", nil
+	case 0x7f, 0x08:
+		return "backspace", nil
+	case 0x03:
+		return "ctrl-c", nil
+	case 0x1b:
+		return t.readEscape(), nil
+	}
+	return string(b), nil
+}
+
+func (t *terminal) readEscape() string {
+	next, ok := t.nextByte()
+	if !ok || (next != '[' && next != 'O') {
+		return "esc"
+	}
+	code, ok := t.nextByte()
+	if !ok {
+		return "esc"
+	}
+	switch code {
+	case 'A':
+		return "up"
+	case 'B':
+		return "down"
+	case 'C':
+		return "right"
+	case 'D':
+		return "left"
+	}
+	return "esc"
+}
+
+func (t *terminal) nextByte() (byte, bool) {
+	select {
+	case b, ok := <-t.keys:
+		return b, ok
+	case <-time.After(25 * time.Millisecond):
+		return 0, false
+	}
+}
+
+func (t *terminal) draw(lines []string, width int) {
+	t.out.WriteString("\x1b[H\x1b[2J")
+	for i, line := range lines {
+		if i > 0 {
+			t.out.WriteString("\r\n")
+		}
+		t.out.WriteString(truncate(line, width))
+	}
+	t.out.Flush()
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func wrap(s string, width int) []string {
+	if width <= 0 {
+		return []string{s}
+	}
+	var lines []string
+	for len(s) > width {
+		lines = append(lines, s[:width])
+		s = s[width:]
+	}
+	return append(lines, s)
+}